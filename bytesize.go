@@ -0,0 +1,118 @@
+package cs
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ByteSize is a number of bytes. It is read from numbers of bytes or from strings with a decimal or binary unit, such
+// as "512MiB", "1.5GB" or "64k"
+type ByteSize uint64
+
+// Decimal and binary byte size units
+const (
+	Byte     ByteSize = 1
+	Kilobyte ByteSize = 1000
+	Megabyte          = 1000 * Kilobyte
+	Gigabyte          = 1000 * Megabyte
+	Terabyte          = 1000 * Gigabyte
+	Petabyte          = 1000 * Terabyte
+	Kibibyte ByteSize = 1024
+	Mebibyte          = 1024 * Kibibyte
+	Gibibyte          = 1024 * Mebibyte
+	Tebibyte          = 1024 * Gibibyte
+	Pebibyte          = 1024 * Tebibyte
+)
+
+// byteSizeUnits maps lower case unit names to their sizes. Single letters are decimal units
+var byteSizeUnits = map[string]ByteSize{
+	"":    Byte,
+	"b":   Byte,
+	"k":   Kilobyte,
+	"kb":  Kilobyte,
+	"m":   Megabyte,
+	"mb":  Megabyte,
+	"g":   Gigabyte,
+	"gb":  Gigabyte,
+	"t":   Terabyte,
+	"tb":  Terabyte,
+	"p":   Petabyte,
+	"pb":  Petabyte,
+	"ki":  Kibibyte,
+	"kib": Kibibyte,
+	"mi":  Mebibyte,
+	"mib": Mebibyte,
+	"gi":  Gibibyte,
+	"gib": Gibibyte,
+	"ti":  Tebibyte,
+	"tib": Tebibyte,
+	"pi":  Pebibyte,
+	"pib": Pebibyte,
+}
+
+// ParseByteSize parses a number of bytes with an optional unit, such as "512MiB", "1.5 GB" or "1024". Units are case
+// insensitive
+func ParseByteSize(s string) (ByteSize, error) {
+
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+	num, unitName := s[:i], strings.ToLower(strings.TrimSpace(s[i:]))
+	if num == "" {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+
+	unit, ok := byteSizeUnits[unitName]
+	if !ok {
+		return 0, fmt.Errorf("invalid byte size %q: unknown unit %q", s, s[i:])
+	}
+
+	if !strings.Contains(num, ".") {
+		n, err := strconv.ParseUint(num, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid byte size %q", s)
+		}
+		if n > math.MaxUint64/uint64(unit) {
+			return 0, fmt.Errorf("byte size %q overflows", s)
+		}
+		return ByteSize(n) * unit, nil
+	}
+
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+	f *= float64(unit)
+	if f >= math.MaxUint64 {
+		return 0, fmt.Errorf("byte size %q overflows", s)
+	}
+	if f != math.Trunc(f) {
+		return 0, fmt.Errorf("byte size %q is not a whole number of bytes", s)
+	}
+	return ByteSize(f), nil
+}
+
+// String formats the size with the largest binary unit which divides it exactly, such as "512MiB"
+func (b ByteSize) String() string {
+	for _, u := range []struct {
+		name string
+		size ByteSize
+	}{
+		{"PiB", Pebibyte},
+		{"TiB", Tebibyte},
+		{"GiB", Gibibyte},
+		{"MiB", Mebibyte},
+		{"KiB", Kibibyte},
+	} {
+		if b >= u.size && b%u.size == 0 {
+			return fmt.Sprintf("%d%s", b/u.size, u.name)
+		}
+	}
+	return fmt.Sprintf("%dB", uint64(b))
+}
@@ -1,8 +1,11 @@
 package cs
 
 import (
+	"context"
+	"math/big"
 	"reflect"
 	"sync"
+	"sync/atomic"
 )
 
 type cacheKey struct {
@@ -10,9 +13,15 @@ type cacheKey struct {
 	Typ reflect.Type
 }
 
+// cachedConfig caches the results of reads until the generation of the delegate changes, which happens whenever
+// sources are added or data is reloaded. Cached values are private copies, so callers never share memory with the
+// cache or each other
 type cachedConfig struct {
-	delegate Config
+	delegate *cs
 	cache    map[cacheKey]reflect.Value
+	gen      uint64
+	hits     atomic.Uint64
+	misses   atomic.Uint64
 	lock     sync.RWMutex
 }
 
@@ -24,49 +33,218 @@ func (c *cachedConfig) AddLateBindingSource(src LateBindingSource) {
 	c.delegate.AddLateBindingSource(src)
 }
 
-func (c *cachedConfig) Read(key string, into any) error {
+func (c *cachedConfig) AddSourceProvider(p SourceProvider) {
+	c.delegate.AddSourceProvider(p)
+}
+
+func (c *cachedConfig) AddLateBindingProvider(p LateBindingProvider) {
+	c.delegate.AddLateBindingProvider(p)
+}
+
+func (c *cachedConfig) RemoveSource(name string) error {
+	return c.delegate.RemoveSource(name)
+}
+
+func (c *cachedConfig) ReplaceSource(name string, p SourceProvider) error {
+	return c.delegate.ReplaceSource(name, p)
+}
+
+func (c *cachedConfig) RemoveLateBindingSource(name string) error {
+	return c.delegate.RemoveLateBindingSource(name)
+}
+
+func (c *cachedConfig) AddWatchableSource(src Source, w Watchable) {
+	c.delegate.AddWatchableSource(src, w)
+}
+
+func (c *cachedConfig) Subscribe(key string, fn func(old, new any)) func() {
+	return c.delegate.Subscribe(key, fn)
+}
+
+func (c *cachedConfig) Close() {
+	c.delegate.Close()
+}
+
+func (c *cachedConfig) AddKeyEnumerator(e KeyEnumerator) {
+	c.delegate.AddKeyEnumerator(e)
+}
+
+func (c *cachedConfig) Has(key string) (bool, error) {
+	return c.delegate.Has(key)
+}
+
+func (c *cachedConfig) Keys(prefix string) ([]string, error) {
+	return c.delegate.Keys(prefix)
+}
+
+func (c *cachedConfig) Children(key string) ([]string, error) {
+	return c.delegate.Children(key)
+}
+
+func (c *cachedConfig) AllSettings() (map[string]any, error) {
+	return c.delegate.AllSettings()
+}
+
+func (c *cachedConfig) Explain(key string) (*Explanation, error) {
+	return c.delegate.Explain(key)
+}
+
+func (c *cachedConfig) Sub(prefix string) Config {
+	return newSubConfig(c, prefix)
+}
+
+func (c *cachedConfig) CacheStats() CacheStats {
 	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return CacheStats{
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+		Entries: len(c.cache),
+	}
+}
+
+func (c *cachedConfig) Read(key string, into any, opts ...Option) error {
+	return c.ReadContext(context.Background(), key, into, opts...)
+}
+
+func (c *cachedConfig) ReadContext(ctx context.Context, key string, into any, opts ...Option) error {
 
 	typ := reflect.TypeOf(into)
 	val := reflect.ValueOf(into)
-	if typ.Kind() == reflect.Ptr {
-		typ = typ.Elem()
-		val = val.Elem()
+	if typ.Kind() != reflect.Ptr || val.IsNil() {
+		// Let the delegate report the error
+		return c.delegate.ReadContext(ctx, key, into, opts...)
+	}
+	typ = typ.Elem()
+	val = val.Elem()
+
+	// Destinations which are already populated are merged with the config, so their result can't be shared. Reads
+	// with their own options may produce different results, so are not cached either
+	if !isEmpty(val) || len(opts) > 0 {
+		c.misses.Add(1)
+		return c.delegate.ReadContext(ctx, key, into, opts...)
+	}
+
+	gen, err := c.delegate.generation(ctx)
+	if err != nil {
+		return err
 	}
 
-	if res, ok := c.cache[cacheKey{
-		Key: key,
-		Typ: typ,
-	}]; ok {
+	ck := cacheKey{Key: key, Typ: typ}
+
+	c.lock.RLock()
+	if res, ok := c.cache[ck]; ok && c.gen == gen {
 		defer c.lock.RUnlock()
-		val.Set(res)
+		c.hits.Add(1)
+		val.Set(deepCopy(res))
 		return nil
 	}
-
 	c.lock.RUnlock()
 
-	c.lock.Lock()
-	defer c.lock.Unlock()
+	c.misses.Add(1)
 
-	err := c.delegate.Read(key, into)
+	err = c.delegate.ReadContext(ctx, key, into)
 	if err != nil {
 		return err
 	}
 
-	c.cache[cacheKey{Key: key, Typ: typ}] = val
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.gen != gen {
+		// Entries from other generations are stale
+		c.cache = map[cacheKey]reflect.Value{}
+		c.gen = gen
+	}
+	c.cache[ck] = deepCopy(val)
 
 	return nil
 }
 
-func (c *cachedConfig) MustRead(key string, into any) {
-	if err := c.Read(key, into); err != nil {
+func (c *cachedConfig) MustRead(key string, into any, opts ...Option) {
+	if err := c.Read(key, into, opts...); err != nil {
 		panic(err)
 	}
 }
 
-func newCachedConfig() Config {
+// isEmpty returns true if the value is zero or an empty map
+func isEmpty(val reflect.Value) bool {
+	if val.Kind() == reflect.Map {
+		return val.Len() == 0
+	}
+	return val.IsZero()
+}
+
+var bigIntType = reflect.TypeFor[big.Int]()
+
+// deepCopy copies a value, including the contents of maps, slices and pointers
+func deepCopy(val reflect.Value) reflect.Value { //nolint:gocyclo // switch statement ok for readability
+
+	if !val.IsValid() {
+		return val
+	}
+
+	res := reflect.New(val.Type()).Elem()
+
+	if val.Type() == bigIntType {
+		// big.Int shares its digits through an unexported slice, so it is copied with Set
+		n := val.Interface().(big.Int)
+		res.Addr().Interface().(*big.Int).Set(&n)
+		return res
+	}
+
+	switch val.Kind() {
+	case reflect.Map:
+		if val.IsNil() {
+			return res
+		}
+		res.Set(reflect.MakeMapWithSize(val.Type(), val.Len()))
+		iter := val.MapRange()
+		for iter.Next() {
+			res.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+	case reflect.Slice:
+		if val.IsNil() {
+			return res
+		}
+		res.Set(reflect.MakeSlice(val.Type(), val.Len(), val.Len()))
+		for i := 0; i < val.Len(); i++ {
+			res.Index(i).Set(deepCopy(val.Index(i)))
+		}
+	case reflect.Array:
+		for i := 0; i < val.Len(); i++ {
+			res.Index(i).Set(deepCopy(val.Index(i)))
+		}
+	case reflect.Struct:
+		// Unexported fields can't be set individually, so they are copied shallowly with the struct
+		res.Set(val)
+		for i := 0; i < val.NumField(); i++ {
+			if res.Field(i).CanSet() {
+				res.Field(i).Set(deepCopy(val.Field(i)))
+			}
+		}
+	case reflect.Ptr:
+		if val.IsNil() {
+			return res
+		}
+		res.Set(reflect.New(val.Type().Elem()))
+		res.Elem().Set(deepCopy(val.Elem()))
+	case reflect.Interface:
+		if val.IsNil() {
+			return res
+		}
+		res.Set(deepCopy(val.Elem()))
+	default:
+		res.Set(val)
+	}
+
+	return res
+}
+
+func newCachedConfig(opts ...Option) Config {
 	return &cachedConfig{
-		delegate: newConfig(),
+		delegate: newConfig(opts...),
 		cache:    map[cacheKey]reflect.Value{},
 	}
 }
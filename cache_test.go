@@ -0,0 +1,161 @@
+package cs_test
+
+import (
+	"math/big"
+	"net/url"
+	"testing"
+
+	"github.com/activatedio/cs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachedConfig_AddSourceAfterRead(t *testing.T) {
+
+	a := assert.New(t)
+
+	unit := cs.NewConfig()
+	unit.AddSource(func() (string, any, error) {
+		return "key1", "a", nil
+	})
+
+	var got string
+	unit.MustRead("key1", &got)
+	a.Equal("a", got)
+
+	unit.AddSource(func() (string, any, error) {
+		return "key1", "b", nil
+	})
+
+	got = ""
+	unit.MustRead("key1", &got)
+	a.Equal("b", got)
+
+	unit.AddLateBindingSource(func(key string) (any, error) {
+		if key == "key1" {
+			return "c", nil
+		}
+		return nil, nil
+	})
+
+	got = ""
+	unit.MustRead("key1", &got)
+	a.Equal("c", got)
+}
+
+func TestCachedConfig_Copies(t *testing.T) {
+
+	a := assert.New(t)
+
+	unit := cs.NewConfig()
+	unit.AddSource(func() (string, any, error) {
+		return "key1", map[string]any{
+			"value1": "a",
+			"list":   []any{"a", "b"},
+		}, nil
+	})
+
+	got1 := map[string]any{}
+	unit.MustRead("key1", &got1)
+	got1["value1"] = "changed"
+	got1["list"].([]any)[0] = "changed"
+
+	got2 := map[string]any{}
+	unit.MustRead("key1", &got2)
+	a.Equal(map[string]any{
+		"value1": "a",
+		"list":   []any{"a", "b"},
+	}, got2)
+
+	got2["value1"] = "changed again"
+
+	var got3 []string
+	unit.MustRead("key1.list", &got3)
+	got3[0] = "changed"
+
+	var got4 []string
+	unit.MustRead("key1.list", &got4)
+	a.Equal([]string{"a", "b"}, got4)
+
+	// Values with unexported storage, such as big.Int, are copied too
+	unit.AddSource(func() (string, any, error) {
+		return "key2", "123456789012345678901234567890", nil
+	})
+
+	var n1 big.Int
+	unit.MustRead("key2", &n1)
+	n1.SetInt64(1)
+
+	var n2 big.Int
+	unit.MustRead("key2", &n2)
+	a.Equal("123456789012345678901234567890", n2.String())
+
+	// Reads which miss the cache return copies of the values of sources
+	n := big.NewInt(5)
+	u := &url.URL{Scheme: "https", Host: "example.com"}
+	unit.AddSource(func() (string, any, error) {
+		return "key3", map[string]any{
+			"n":   n,
+			"url": u,
+		}, nil
+	})
+
+	var n3 *big.Int
+	unit.MustRead("key3.n", &n3)
+	n3.SetInt64(99)
+
+	var u3 *url.URL
+	unit.MustRead("key3.url", &u3)
+	u3.Host = "changed.com"
+
+	a.Equal("5", n.String())
+	a.Equal("example.com", u.Host)
+
+	// Adding a source discards the cache, so these reads miss it again
+	unit.AddSource(func() (string, any, error) {
+		return "key4", "d", nil
+	})
+
+	var n4 *big.Int
+	unit.MustRead("key3.n", &n4)
+	a.Equal("5", n4.String())
+
+	var u4 *url.URL
+	unit.MustRead("key3.url", &u4)
+	a.Equal("example.com", u4.Host)
+}
+
+func TestCachedConfig_Stats(t *testing.T) {
+
+	a := assert.New(t)
+
+	unit := cs.NewConfig()
+	unit.AddSource(func() (string, any, error) {
+		return "key1", "a", nil
+	})
+
+	var got string
+	unit.MustRead("key1", &got)
+	got = ""
+	unit.MustRead("key1", &got)
+	got = ""
+	unit.MustRead("key1", &got)
+
+	a.Equal(cs.CacheStats{
+		Hits:    2,
+		Misses:  1,
+		Entries: 1,
+	}, unit.CacheStats())
+
+	unit.AddSource(func() (string, any, error) {
+		return "key2", "b", nil
+	})
+
+	got = ""
+	unit.MustRead("key1", &got)
+
+	a.Equal(cs.CacheStats{
+		Hits:    2,
+		Misses:  2,
+		Entries: 1,
+	}, unit.CacheStats())
+}
@@ -1,70 +1,161 @@
 package cs
 
 import (
+	"context"
+	"encoding"
 	"errors"
 	"fmt"
 	"reflect"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/spf13/cast"
 )
 
 type cs struct {
-	sources            []Source
-	lateBindingSources []LateBindingSource
+	sources            []*sourceEntry
+	lateBindingSources []*lateBindingEntry
+	keyEnumerators     []KeyEnumerator
+	nextSourceID       int
+	nextLateBindingID  int
 	dirty              bool
+	loaded             bool
+	closed             bool
+	gen                uint64
 	root               map[string]reflect.Value
-	lock               sync.RWMutex
+	origins            map[string][]Layer
+	subscriptions      map[int]*subscription
+	nextSubscriptionID int
+	// opts are the options applied to every read
+	opts readOptions
+	// loading is closed when the load in progress, if any, completes
+	loading chan struct{}
+	// loadingSource is the name of the source currently being loaded, reported to reads which time out waiting
+	loadingSource atomic.Value
+	lock          sync.RWMutex
 }
 
-func (c *cs) AddSource(src Source) {
+// loadData builds a new root from the sources and swaps it in. Sources are loaded without holding the lock, so reads
+// of other keys which don't need a load, and reads waiting for this load with their own context, aren't blocked by
+// slow sources
+func (c *cs) loadData(ctx context.Context) error {
+
 	c.lock.Lock()
-	defer c.lock.Unlock()
 
-	c.sources = append(c.sources, src)
-	c.dirty = true
-}
+	if loading := c.loading; loading != nil {
+		// A load is already in progress, so wait for it rather than starting another. Whether it succeeded is checked
+		// by the caller, which loads again if the data is still dirty
+		c.lock.Unlock()
+		select {
+		case <-loading:
+			return nil
+		case <-ctx.Done():
+			source, _ := c.loadingSource.Load().(string)
+			return &TimeoutError{Source: source, Err: ctx.Err()}
+		}
+	}
 
-func (c *cs) AddLateBindingSource(src LateBindingSource) {
-	c.lock.Lock()
-	defer c.lock.Unlock()
+	loading := make(chan struct{})
+	c.loading = loading
+	gen := c.gen
+	sources := slices.Clone(c.sources)
 
-	c.lateBindingSources = append(c.lateBindingSources, src)
-	c.dirty = true
-}
+	c.lock.Unlock()
 
-func (c *cs) loadData() error {
+	// The new root is only swapped in once all sources have loaded, so a failed reload never leaves partial data
+	root, origins, err := c.buildRoot(ctx, sources)
 
 	c.lock.Lock()
-	defer c.lock.Unlock()
 
-	c.root = make(map[string]reflect.Value)
+	c.loading = nil
+	close(loading)
 
-	for _, src := range c.sources {
-		key, v, err := src()
-		if err != nil {
-			return err
-		}
-		var val reflect.Value
-		val, err = c.toValue(v)
+	if err != nil {
+		c.lock.Unlock()
+		return err
+	}
+
+	old := c.root
+	notify := c.loaded
+	c.root = root
+	c.origins = origins
+	// Sources which changed while loading leave the cs dirty, so the next read loads again
+	c.dirty = c.gen != gen
+	c.loaded = true
+	c.gen++
+	subs := c.subscribers()
+
+	c.lock.Unlock()
+
+	// Subscribers are only notified of changes after the initial load
+	if notify {
+		c.notifySubscribers(subs, old, root)
+	}
+
+	return nil
+}
+
+func (c *cs) buildRoot(ctx context.Context, sources []*sourceEntry) (map[string]reflect.Value, map[string][]Layer,
+	error) {
+
+	root := make(map[string]reflect.Value)
+	origins := make(map[string][]Layer)
+
+	for _, entry := range sources {
+		name := entry.provider.Name()
+		c.loadingSource.Store(name)
+		key, v, err := loadWithContext(ctx, entry.provider)
 		if err != nil {
-			return err
+			return nil, nil, sourceError(name, "", err)
 		}
-		var tmp map[string]reflect.Value
-		tmp, err = c.toValueMap(key, val)
-		if err != nil {
-			return err
+		layers, ok := v.(Layers)
+		if !ok {
+			layers = Layers{v}
 		}
-		// We ignore return as maps are never replaced
-		_, err = c.replaceOrMergeValues(reflect.ValueOf(c.root), reflect.ValueOf(tmp))
-		if err != nil {
-			return err
+		for _, layer := range layers {
+			if err = c.addLayer(root, origins, name, entry.autoNamed, key, layer); err != nil {
+				return nil, nil, err
+			}
 		}
 	}
 
-	c.dirty = false
+	return root, origins, nil
+}
 
+// addLayer merges a value supplied by a source into root, recording its origins
+func (c *cs) addLayer(root map[string]reflect.Value, origins map[string][]Layer, name string, autoNamed bool, key string,
+	v any) error {
+
+	origin := Origin{}
+	var lines map[string]int
+	if _v, a := unwrapAnnotated(v); a != nil {
+		v = _v
+		origin = a.Origin
+		lines = a.Lines
+	}
+	origin.Source = sourceName(origin.Source, name, autoNamed)
+	val, err := c.toValue(key, v)
+	if err != nil {
+		return sourceError(name, "", err)
+	}
+	if !val.IsValid() {
+		// A source returning nil contributes nothing
+		return nil
+	}
+	var tmp map[string]reflect.Value
+	tmp, err = c.toValueMap(key, val)
+	if err != nil {
+		return sourceError(name, key, err)
+	}
+	// We ignore return as maps are never replaced
+	_, err = c.replaceOrMergeValues("", name, reflect.ValueOf(root), reflect.ValueOf(tmp))
+	if err != nil {
+		return err
+	}
+	recordOrigins(origins, key, val, origin, lines)
 	return nil
 }
 
@@ -74,7 +165,7 @@ func (c *cs) toValueMap(key string, v reflect.Value) (map[string]reflect.Value,
 		if val, ok := v.Interface().(map[string]reflect.Value); ok {
 			return val, nil
 		}
-		return nil, errors.New("invalid root type")
+		return nil, fmt.Errorf("a source without a key must supply a map, got %s", kindName(v))
 	}
 	// Build out a map structure
 	val := map[string]reflect.Value{}
@@ -93,13 +184,29 @@ func (c *cs) toValueMap(key string, v reflect.Value) (map[string]reflect.Value,
 	return val, nil
 }
 
-func (c *cs) toValue(v any) (reflect.Value, error) {
+// toValue converts a value from a source to its internal representation. The path is the full key of the value, used
+// in errors. Nil values, including nil pointers, are returned as an invalid value and treated as absent
+func (c *cs) toValue(path string, v any) (reflect.Value, error) {
+	v, _ = unwrapAnnotated(v)
 	typ := reflect.TypeOf(v)
 
-	if typ.Kind() == reflect.Ptr {
-		typ = typ.Elem()
-		// We assume we can interface this
-		v = reflect.Indirect(reflect.ValueOf(v)).Interface()
+	// Pointers and interfaces are followed to the value they hold, unless they are well known types such as *url.URL
+	for typ != nil && !isWellKnown(typ) && (typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Interface) {
+		rv := reflect.ValueOf(v)
+		if rv.IsNil() {
+			return reflect.Value{}, nil
+		}
+		v = rv.Elem().Interface()
+		typ = reflect.TypeOf(v)
+	}
+
+	if typ == nil {
+		return reflect.Value{}, nil
+	}
+
+	if isWellKnown(typ) {
+		// Well known types such as time.Time are stored as they are
+		return reflect.ValueOf(v), nil
 	}
 
 	switch typ.Kind() {
@@ -109,104 +216,253 @@ func (c *cs) toValue(v any) (reflect.Value, error) {
 		reflect.Bool:
 		return reflect.ValueOf(v), nil
 	case reflect.Map:
-		return c.toValueFromMap(v)
+		return c.toValueFromMap(path, v)
 	case reflect.Struct:
-		return c.toValueFromStruct(v)
+		return c.toValueFromStruct(path, reflect.ValueOf(v))
+	case reflect.Slice, reflect.Array:
+		return c.toValueFromSlice(path, v)
 	default:
-		return reflect.ValueOf(nil), fmt.Errorf("unsupported kind %s", typ.Kind().String())
+		return reflect.ValueOf(nil), &KeyError{Key: path, Err: fmt.Errorf("unsupported kind %s", typ.Kind().String())}
 	}
 }
 
-func (c *cs) toValueFromMap(v any) (reflect.Value, error) {
-	// We assume this is a struct and convert this to a map of values
+func (c *cs) toValueFromSlice(path string, v any) (reflect.Value, error) {
+	// Lists are stored as a slice of values, with each element converted in the same way as a single value
+	val := reflect.ValueOf(v)
+	res := make([]reflect.Value, 0, val.Len())
+
+	for i := 0; i < val.Len(); i++ {
+		ev, err := c.toValue(joinKey(path, strconv.Itoa(i)), val.Index(i).Interface())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		res = append(res, ev)
+	}
+
+	return reflect.ValueOf(res), nil
+}
+
+// toValueFromMap converts any map with keys which can be represented as strings, such as map[string]string,
+// map[any]any from older yaml decoders or maps keyed by numbers, to a map of values. Nil entries are skipped
+func (c *cs) toValueFromMap(path string, v any) (reflect.Value, error) {
 	res := map[string]reflect.Value{}
-	if val, ok := v.(map[string]any); ok {
-		for k, _v := range val {
-			fv, err := c.toValue(_v)
-			if err != nil {
-				return reflect.Value{}, err
-			}
+	iter := reflect.ValueOf(v).MapRange()
+	for iter.Next() {
+		k, err := mapKeyString(iter.Key())
+		if err != nil {
+			return reflect.Value{}, &KeyError{Key: path, Err: err}
+		}
+		fv, err := c.toValue(joinKey(path, k), iter.Value().Interface())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if fv.IsValid() {
 			res[k] = fv
 		}
-	} else {
-		return reflect.ValueOf(nil), errors.New("map must be of type map[string]any")
 	}
 
 	return reflect.ValueOf(res), nil
 }
 
-func (c *cs) toValueFromStruct(v any) (reflect.Value, error) {
+// mapKeyString converts the key of a source map to a string
+func mapKeyString(key reflect.Value) (string, error) {
+	if key.Kind() == reflect.Interface {
+		if key.IsNil() {
+			return "", errors.New("unsupported nil map key")
+		}
+		key = key.Elem()
+	}
+	if m, ok := key.Interface().(encoding.TextMarshaler); ok {
+		b, err := m.MarshalText()
+		return string(b), err
+	}
+	switch key.Kind() {
+	case reflect.String:
+		return key.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64,
+		reflect.Bool:
+		return fmt.Sprint(key.Interface()), nil
+	default:
+		return "", fmt.Errorf("unsupported map key type %s", key.Type().String())
+	}
+}
+
+func (c *cs) toValueFromStruct(path string, val reflect.Value) (reflect.Value, error) {
 	// We assume this is a struct and convert this to a map of values
 	res := map[string]reflect.Value{}
-	val := reflect.ValueOf(v)
 
 	for i := 0; i < val.NumField(); i++ {
 		f := val.Field(i)
-		if f.CanInterface() {
-			fv, err := c.toValue(f.Interface())
+		info, ok := parseField(val.Type().Field(i))
+		if !ok || (info.omitEmpty && f.IsZero()) {
+			continue
+		}
+		if info.squash {
+			if f.Kind() == reflect.Ptr {
+				if f.IsNil() {
+					continue
+				}
+				f = f.Elem()
+			}
+			// Squashed fields are merged into this map, taking precedence over fields declared earlier. The field is
+			// walked without being interfaced, as embedded structs may be unexported while their fields are not
+			sv, err := c.toValueFromStruct(path, f)
 			if err != nil {
 				return reflect.Value{}, err
 			}
-			res[toLowerCamel(val.Type().Field(i).Name)] = fv
+			for k, _v := range sv.Interface().(map[string]reflect.Value) {
+				res[k] = _v
+			}
+			continue
+		}
+		var fv reflect.Value
+		var err error
+		if f.CanInterface() {
+			fv, err = c.toValue(joinKey(path, info.name), f.Interface())
+		} else {
+			// Unexported embedded structs given a name are walked in the same way as squashed ones
+			fv, err = c.toValueFromStruct(joinKey(path, info.name), f)
+		}
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if fv.IsValid() {
+			res[info.name] = fv
 		}
 	}
 
 	return reflect.ValueOf(res), nil
 }
 
-func (c *cs) fromValue(fullKey string, val reflect.Value, into any) error {
+func (c *cs) fromValue(rs *readState, fullKey string, val reflect.Value, into any) error {
 	dest := reflect.ValueOf(into)
 	if dest.Kind() == reflect.Ptr {
 		dest = dest.Elem()
 	}
-	return c.populateValue(fullKey, dest, val)
+	err := c.populateValue(rs, fullKey, dest, val)
+	if err != nil {
+		return err
+	}
+	return validate(fullKey, dest)
+}
+
+// lateBindingValue returns the value from the last late binding source which supplies one for a key, along with the
+// name of that source
+func (c *cs) lateBindingValue(rs *readState, fullKey string) (any, string, error) {
+	var res any
+	var name string
+	for _, entry := range c.lateBindingSources {
+		lbVal, err := lookupWithContext(rs.ctx, entry.provider, fullKey)
+		if err != nil {
+			return nil, "", sourceError(entry.provider.Name(), fullKey, err)
+		}
+		if lbVal, _ = unwrapAnnotated(lbVal); lbVal != nil {
+			res = lbVal
+			name = entry.provider.Name()
+		}
+	}
+	if res != nil {
+		rs.lateBound++
+	}
+	return res, name, nil
 }
 
-func (c *cs) populateValue(fullKey string, dest reflect.Value, val reflect.Value) error {
+// sourceOf returns the name of the source which supplied the value of a key, checking parent keys for elements of
+// lists. Must be called with the lock held
+func (c *cs) sourceOf(fullKey string) string {
+	for key := fullKey; key != ""; {
+		if layers := c.origins[key]; len(layers) > 0 {
+			return layers[len(layers)-1].Origin.Source
+		}
+		idx := strings.LastIndex(key, ".")
+		if idx < 0 {
+			break
+		}
+		key = key[:idx]
+	}
+	return ""
+}
+
+// conversionError describes a value which couldn't be converted. Values from sources are attributed to the source
+// which supplied them, unless a late binding source supplied the value
+func (c *cs) conversionError(fullKey, lbName string, dest, val reflect.Value, err error) error {
+	if lbName == "" {
+		lbName = c.sourceOf(fullKey)
+	}
+	return &ConversionError{
+		Key:      fullKey,
+		Source:   lbName,
+		Value:    toInterface(val),
+		Expected: dest.Type(),
+		Actual:   naturalType(val),
+		Err:      err,
+	}
+}
+
+func (c *cs) populateValue(rs *readState, fullKey string, dest reflect.Value, val reflect.Value) error {
+	if decode := decoderFor(rs.opts, dest.Type()); decode != nil {
+		return c.populateDecoded(rs, fullKey, dest, val, decode)
+	}
 	switch dest.Kind() {
 	case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
 		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
 		reflect.Float32, reflect.Float64,
 		reflect.Bool:
 
-		for _, src := range c.lateBindingSources {
-			lbVal, err := src(fullKey)
-			if err != nil {
-				return err
-			}
-			if lbVal != nil {
-				val = reflect.ValueOf(lbVal)
-			}
+		lbVal, lbName, err := c.lateBindingValue(rs, fullKey)
+		if err != nil {
+			return err
+		}
+		if lbVal != nil {
+			val = reflect.ValueOf(lbVal)
+		}
+
+		if !val.IsValid() && rs.opts.required[fullKey] {
+			return &KeyError{Key: fullKey, Err: ErrKeyNotFound}
 		}
 
 		// This skips the set in case of a zero value
 		if val.IsValid() {
 			// Need some type conversions, especially given some of the late binding sources will be strings from
 			// environment variables
-			err := c.castAndSet(dest, val)
+			err := c.castAndSet(dest, val, rs.opts.strictConversion)
 			if err != nil {
-				return err
+				return c.conversionError(fullKey, lbName, dest, val, err)
 			}
 		}
 		return nil
 	case reflect.Map:
 		// We need to be able to write to the struct
-		return c.populateMap(fullKey, dest, val)
+		return c.populateMap(rs, fullKey, dest, val)
 	case reflect.Struct:
 		// We need to be able to write to the struct
-		return c.populateStruct(fullKey, dest, val)
+		return c.populateStruct(rs, fullKey, dest, val)
+	case reflect.Slice, reflect.Array:
+		return c.populateSlice(rs, fullKey, dest, val)
+	case reflect.Ptr:
+		return c.populatePtr(rs, fullKey, dest, val)
+	case reflect.Interface:
+		return c.populateInterface(rs, fullKey, dest, val)
 	default:
-		return fmt.Errorf("unsupported destination kind %s", dest.Kind().String())
+		return &KeyError{Key: fullKey, Err: fmt.Errorf("unsupported destination kind %s", dest.Kind().String())}
 	}
 }
 
-func (c *cs) castAndSet(dest, src reflect.Value) error { //nolint:gocyclo // switch statement ok for readability
+// castAndSet converts a value to the type of its destination. In strict mode conversions which lose information fail,
+// otherwise they fall back to the zero value
+func (c *cs) castAndSet(dest, src reflect.Value, strict bool) error { //nolint:gocyclo // switch statement ok for readability
 
 	if dest.Type() == src.Type() {
 		dest.Set(src)
 		return nil
 	}
 
+	if strict {
+		return strictCastAndSet(dest, src)
+	}
+
 	var val any
 
 	switch dest.Kind() {
@@ -242,15 +498,128 @@ func (c *cs) castAndSet(dest, src reflect.Value) error { //nolint:gocyclo // swi
 		return fmt.Errorf("unsupported type %s", dest.Type().String())
 	}
 
-	dest.Set(reflect.ValueOf(val))
+	// Named types such as `type Level int` are converted from their underlying type
+	dest.Set(reflect.ValueOf(val).Convert(dest.Type()))
 
 	return nil
 }
 
 var typeMapStringReflectValue = reflect.TypeFor[map[string]reflect.Value]()
 var typeMapStringAny = reflect.TypeFor[map[string]any]()
+var typeSliceReflectValue = reflect.TypeFor[[]reflect.Value]()
+var typeSliceAny = reflect.TypeFor[[]any]()
+var typeAny = reflect.TypeFor[any]()
+
+// naturalType returns the destination type used when populating an untyped destination such as map[string]any
+func naturalType(val reflect.Value) reflect.Type {
+	if !val.IsValid() {
+		// Nil elements of lists
+		return typeAny
+	}
+	switch val.Type() {
+	case typeMapStringReflectValue:
+		return typeMapStringAny
+	case typeSliceReflectValue:
+		return typeSliceAny
+	default:
+		return val.Type()
+	}
+}
+
+// toList converts a value into a list of values. Strings, which typically come from late binding sources such as
+// environment variables, are split on commas.
+func (c *cs) toList(fullKey string, val reflect.Value) ([]reflect.Value, error) {
+	if l, ok := val.Interface().([]reflect.Value); ok {
+		return l, nil
+	}
+	if s, ok := val.Interface().(string); ok {
+		var res []reflect.Value
+		for _, part := range strings.Split(s, ",") {
+			res = append(res, reflect.ValueOf(strings.TrimSpace(part)))
+		}
+		return res, nil
+	}
+	if val.Kind() == reflect.Slice || val.Kind() == reflect.Array {
+		tmp, err := c.toValueFromSlice(fullKey, val.Interface())
+		if err != nil {
+			return nil, err
+		}
+		return tmp.Interface().([]reflect.Value), nil
+	}
+	if val.Kind() == reflect.Map {
+		return nil, errors.New("cannot populate a list from a map")
+	}
+	// A single value becomes a list of one
+	return []reflect.Value{val}, nil
+}
+
+func (c *cs) populateSlice(rs *readState, fullKey string, dest reflect.Value, val reflect.Value) error {
 
-func (c *cs) populateMap(fullKey string, dest reflect.Value, val reflect.Value) error {
+	lbVal, lbName, err := c.lateBindingValue(rs, fullKey)
+	if err != nil {
+		return err
+	}
+	if lbVal != nil {
+		val = reflect.ValueOf(lbVal)
+	}
+
+	if !val.IsValid() {
+		if rs.opts.required[fullKey] {
+			return &KeyError{Key: fullKey, Err: ErrKeyNotFound}
+		}
+		// Nothing to populate
+		return nil
+	}
+
+	if dest.Kind() == reflect.Slice && dest.Type().Elem().Kind() == reflect.Uint8 {
+		// Special case for []byte, which is populated directly from strings
+		if s, ok := val.Interface().(string); ok {
+			dest.SetBytes([]byte(s))
+			return nil
+		}
+	}
+
+	list, err := c.toList(fullKey, val)
+	if err != nil {
+		return c.conversionError(fullKey, lbName, dest, val, err)
+	}
+
+	if dest.Kind() == reflect.Array {
+		if len(list) > dest.Len() {
+			err = fmt.Errorf("too many values: array has length %d but got %d", dest.Len(), len(list))
+			return c.conversionError(fullKey, lbName, dest, val, err)
+		}
+	} else {
+		dest.Set(reflect.MakeSlice(dest.Type(), len(list), len(list)))
+	}
+
+	// Every element is populated so a single read reports all failures
+	var errs []error
+	for i, el := range list {
+		_fullKey := joinKey(fullKey, strconv.Itoa(i))
+		_dest := dest.Index(i)
+		if _dest.Kind() == reflect.Interface {
+			// Untyped elements are populated with their natural types
+			tmp := reflect.New(naturalType(el)).Elem()
+			if tmp.Kind() == reflect.Map {
+				tmp.Set(reflect.MakeMap(tmp.Type()))
+			}
+			if err = c.populateValue(rs, _fullKey, tmp, el); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			_dest.Set(tmp)
+			continue
+		}
+		if err = c.populateValue(rs, _fullKey, _dest, el); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return joinErrors(errs)
+}
+
+func (c *cs) populateMap(rs *readState, fullKey string, dest reflect.Value, val reflect.Value) error {
 
 	// type must be map[string]reflect.Value
 	if val.Kind() != reflect.Map {
@@ -258,51 +627,194 @@ func (c *cs) populateMap(fullKey string, dest reflect.Value, val reflect.Value)
 		return nil
 	}
 
-	vInt := val.Interface()
-	dInt := dest.Interface()
-
-	if _, ok := vInt.(map[string]reflect.Value); !ok {
+	if _, ok := val.Interface().(map[string]reflect.Value); !ok {
 		return errors.New("invalid internal map type. must be map[string]reflect.Value")
 	}
 
-	if _, ok := dInt.(map[string]any); !ok {
-		return errors.New("invalid destination map type. must be map[string]any")
+	keyType := dest.Type().Key()
+	elemType := dest.Type().Elem()
+
+	if dest.IsNil() {
+		dest.Set(reflect.MakeMap(dest.Type()))
 	}
 
+	var errs []error
 	for _, key := range val.MapKeys() {
-		exist := dest.MapIndex(key)
-		var _fullKey string
-		if fullKey == "" {
-			_fullKey = toLowerCamel(key.String())
-		} else {
-			_fullKey = fmt.Sprintf("%s.%s", fullKey, toLowerCamel(key.String()))
+		_fullKey := joinKey(fullKey, toLowerCamel(key.String()))
+		tmp := val.MapIndex(key).Interface().(reflect.Value)
+		_key, err := mapKey(keyType, key.String())
+		if err != nil {
+			errs = append(errs, c.conversionError(_fullKey, "", reflect.New(keyType).Elem(), key, err))
+			continue
 		}
-		_val := val.MapIndex(key)
-		if exist.IsValid() {
-			err := c.populateValue(_fullKey, exist, _val)
-			if err != nil {
-				return err
+		exist := dest.MapIndex(_key)
+		if elemType.Kind() == reflect.Interface && elemType.NumMethod() == 0 {
+			// Untyped elements are populated with their natural types
+			_dest := reflect.New(naturalType(tmp)).Elem()
+			switch {
+			case exist.IsValid() && exist.Elem().IsValid() && exist.Elem().Type() == _dest.Type() && _dest.Kind() == reflect.Map:
+				// Existing maps are populated in place
+				_dest = exist.Elem()
+			case _dest.Kind() == reflect.Map:
+				_dest.Set(reflect.MakeMap(_dest.Type()))
 			}
-		} else {
-			tmp := _val.Interface().(reflect.Value)
-			_type := tmp.Type()
-			var _dest reflect.Value
-			if _type == typeMapStringReflectValue {
-				_dest = reflect.MakeMap(typeMapStringAny)
-			} else {
-				_dest = reflect.New(_type).Elem()
+			if err = c.populateValue(rs, _fullKey, _dest, tmp); err != nil {
+				errs = append(errs, err)
+				continue
 			}
-			err := c.populateValue(_fullKey, _dest, tmp)
-			if err != nil {
+			dest.SetMapIndex(_key, _dest)
+			continue
+		}
+		// Typed elements are populated over a copy of any existing element, so late binding sources, defaults and
+		// conversions apply per entry. Existing pointers are copied on write by populatePtr
+		_dest := reflect.New(elemType).Elem()
+		if exist.IsValid() {
+			_dest.Set(exist)
+		}
+		if err = c.populateValue(rs, _fullKey, _dest, tmp); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if _dest.Kind() == reflect.Ptr && _dest.IsNil() {
+			continue
+		}
+		dest.SetMapIndex(_key, _dest)
+	}
+	return joinErrors(errs)
+}
+
+// mapKey converts a key to the key type of a map. Keys of types implementing encoding.TextUnmarshaler are unmarshaled,
+// other keys must convert exactly
+func mapKey(keyType reflect.Type, key string) (reflect.Value, error) {
+	res := reflect.New(keyType)
+	if u, ok := res.Interface().(encoding.TextUnmarshaler); ok {
+		if err := u.UnmarshalText([]byte(key)); err != nil {
+			return reflect.Value{}, err
+		}
+		return res.Elem(), nil
+	}
+	switch keyType.Kind() {
+	case reflect.String:
+		res.Elem().SetString(key)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64,
+		reflect.Bool:
+		if err := strictCastAndSet(res.Elem(), reflect.ValueOf(key)); err != nil {
+			return reflect.Value{}, err
+		}
+	default:
+		return reflect.Value{}, fmt.Errorf("unsupported map key type %s", keyType.String())
+	}
+	return res.Elem(), nil
+}
+
+// present returns true if a value exists for a key, either from sources, late binding sources or the keys listed by
+// key enumerators
+func (c *cs) present(rs *readState, fullKey string, val reflect.Value) (bool, error) {
+	if val.IsValid() {
+		return true, nil
+	}
+	lbVal, _, err := c.lateBindingValue(rs, fullKey)
+	if err != nil || lbVal != nil {
+		return lbVal != nil, err
+	}
+	for _, k := range c.enumeratedKeys() {
+		if k == fullKey || strings.HasPrefix(k, fullKey+".") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// populatePtr populates the value a pointer points to. Pointers are only allocated when a value exists, so a nil
+// pointer means the key is not configured. Existing pointers are copied rather than written through, as the value
+// they point to may be shared
+func (c *cs) populatePtr(rs *readState, fullKey string, dest reflect.Value, val reflect.Value) error {
+
+	ok, err := c.present(rs, fullKey, val)
+	if err != nil {
+		return err
+	}
+
+	ptr := reflect.New(dest.Type().Elem())
+	if !dest.IsNil() {
+		ptr.Elem().Set(dest.Elem())
+	}
+
+	if !ok {
+		if kind := dest.Type().Elem().Kind(); kind == reflect.Struct || kind == reflect.Ptr {
+			// Late binding sources can't be enumerated, so they may still supply keys beneath this one. The value is
+			// populated, and only kept if a late binding source supplied part of it
+			before := rs.lateBound
+			err = c.populateValue(rs, fullKey, ptr.Elem(), val)
+			if rs.lateBound > before {
+				if err != nil {
+					return err
+				}
+				dest.Set(ptr)
+				return nil
+			}
+			// Errors from fields of a value which isn't configured, such as missing required keys, don't apply,
+			// while failing sources still do
+			if isSourceFailure(err) {
 				return err
 			}
-			dest.SetMapIndex(key, _dest)
 		}
+		if rs.opts.required[fullKey] {
+			return &KeyError{Key: fullKey, Err: ErrKeyNotFound}
+		}
+		return nil
+	}
+
+	if err = c.populateValue(rs, fullKey, ptr.Elem(), val); err != nil {
+		return err
+	}
+	dest.Set(ptr)
+
+	return nil
+}
+
+// populateInterface populates an interface with the natural go value, using map[string]any for maps and []any for
+// lists
+func (c *cs) populateInterface(rs *readState, fullKey string, dest reflect.Value, val reflect.Value) error {
+
+	lbVal, _, err := c.lateBindingValue(rs, fullKey)
+	if err != nil {
+		return err
+	}
+	if lbVal != nil {
+		val = reflect.ValueOf(lbVal)
+	}
+
+	if !val.IsValid() {
+		if rs.opts.required[fullKey] {
+			return &KeyError{Key: fullKey, Err: ErrKeyNotFound}
+		}
+		return nil
+	}
+
+	tmp := reflect.New(naturalType(val)).Elem()
+	if !tmp.Type().AssignableTo(dest.Type()) {
+		return c.conversionError(fullKey, "", dest, val, fmt.Errorf("%s does not implement %s", tmp.Type(), dest.Type()))
 	}
+	if tmp.Kind() == reflect.Map {
+		tmp.Set(reflect.MakeMap(tmp.Type()))
+	}
+	if err = c.populateValue(rs, fullKey, tmp, val); err != nil {
+		return err
+	}
+	dest.Set(tmp)
+
 	return nil
 }
 
-func (c *cs) populateStruct(fullKey string, dest reflect.Value, val reflect.Value) error {
+func (c *cs) populateStruct(rs *readState, fullKey string, dest reflect.Value, val reflect.Value) error {
+
+	if !val.IsValid() {
+		// Fields are still populated so defaults and late binding sources apply
+		val = reflect.ValueOf(map[string]reflect.Value{})
+	}
 
 	// type must be map[string]reflect.Value
 	if val.Kind() != reflect.Map {
@@ -310,41 +822,144 @@ func (c *cs) populateStruct(fullKey string, dest reflect.Value, val reflect.Valu
 		return nil
 	}
 
-	// TODO - consider keys which are not present in the map
+	valMap, valMapOk := val.Interface().(map[string]reflect.Value)
+	if !valMapOk {
+		// Can't do anything, return nil
+		return nil
+	}
+
+	// Every field is populated so a single read reports all failures
+	errs := c.populateFields(rs, fullKey, dest, valMap)
+
+	if rs.opts.strictKeys {
+		errs = append(errs, unknownKeys(fullKey, dest.Type(), valMap)...)
+	}
+
+	return joinErrors(errs)
+}
 
-	if valMap, valMapOk := val.Interface().(map[string]reflect.Value); valMapOk {
+// populateFields populates the fields of a struct from a map, including the fields of squashed structs, returning any
+// errors
+func (c *cs) populateFields(rs *readState, fullKey string, dest reflect.Value, valMap map[string]reflect.Value) []error {
 
-		for i := 0; i < dest.NumField(); i++ {
-			f := dest.Field(i)
-			name := toLowerCamel(dest.Type().Field(i).Name)
-			v := valMap[name]
-			err := c.populateValue(fmt.Sprintf("%s.%s", fullKey, name), f, v)
+	var errs []error
+	for i := 0; i < dest.NumField(); i++ {
+		f := dest.Field(i)
+		info, ok := parseField(dest.Type().Field(i))
+		if !ok {
+			continue
+		}
+		if info.squash && f.Kind() == reflect.Struct {
+			// Squashed fields read from the same map as their parent
+			errs = append(errs, c.populateFields(rs, fullKey, f, valMap)...)
+			continue
+		}
+		if info.squash && f.Kind() == reflect.Ptr && f.Type().Elem().Kind() == reflect.Struct {
+			errs = append(errs, c.populateSquashedPtr(rs, fullKey, f, valMap)...)
+			continue
+		}
+		_fullKey := joinKey(fullKey, info.name)
+		v := valMap[info.name]
+		if !v.IsValid() && (info.hasDefault || info.required) {
+			lbVal, _, err := c.lateBindingValue(rs, _fullKey)
 			if err != nil {
-				return err
+				errs = append(errs, err)
+				continue
+			}
+			if lbVal == nil {
+				if info.required {
+					errs = append(errs, &KeyError{Key: _fullKey, Err: ErrKeyNotFound})
+					continue
+				}
+				v = reflect.ValueOf(info.defaultValue)
 			}
 		}
-	} else {
-		// Can't do anything, return nil
-		return nil
+		if err := c.populateValue(rs, _fullKey, f, v); err != nil {
+			errs = append(errs, err)
+		}
 	}
 
-	return nil
+	return errs
+}
+
+// populateSquashedPtr populates a squashed pointer to a struct from the map of its parent. As with other pointers, it
+// is only allocated if any of its fields are configured
+func (c *cs) populateSquashedPtr(rs *readState, fullKey string, dest reflect.Value, valMap map[string]reflect.Value) []error {
+
+	ptr := reflect.New(dest.Type().Elem())
+	if !dest.IsNil() {
+		ptr.Elem().Set(dest.Elem())
+	}
+
+	before := rs.lateBound
+	errs := c.populateFields(rs, fullKey, ptr.Elem(), valMap)
+
+	configured := rs.lateBound > before
+	if !configured {
+		names := map[string]bool{}
+		collectFieldNames(ptr.Elem().Type(), names)
+		for k := range valMap {
+			if names[k] {
+				configured = true
+				break
+			}
+		}
+	}
+
+	if !configured {
+		var res []error
+		for _, err := range errs {
+			if isSourceFailure(err) {
+				res = append(res, err)
+			}
+		}
+		return res
+	}
+
+	dest.Set(ptr)
+	return errs
 }
 
-func (c *cs) replaceOrMergeValues(existing reflect.Value, value reflect.Value) (reflect.Value, error) {
+// isSourceFailure returns true if an error was caused by a source failing or timing out
+func isSourceFailure(err error) bool {
+	var te *TimeoutError
+	var se *SourceError
+	return errors.As(err, &te) || errors.As(err, &se)
+}
+
+// replaceOrMergeValues merges a value from a source into the existing value at a key. The key and source name are used
+// in errors
+func (c *cs) replaceOrMergeValues(key, source string, existing reflect.Value, value reflect.Value) (reflect.Value, error) {
+
+	conflict := func() error {
+		return &MergeConflictError{
+			Key:      key,
+			Source:   source,
+			Existing: kindName(existing),
+			Incoming: kindName(value),
+		}
+	}
 
 	switch existing.Kind() {
 	case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
 		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
 		reflect.Float32, reflect.Float64,
-		reflect.Bool:
+		reflect.Bool,
+		// Values of well known types such as time.Time
+		reflect.Struct, reflect.Ptr:
+		if value.Kind() == reflect.Map {
+			return reflect.Value{}, conflict()
+		}
+		return value, nil
+	case reflect.Slice:
+		// Lists are never merged element by element. A list from a later source replaces the earlier one entirely
 		if value.Kind() == reflect.Map {
-			return reflect.Value{}, fmt.Errorf("cannot overrwrite type %s with a map", existing.Kind().String())
+			return reflect.Value{}, conflict()
 		}
 		return value, nil
 	case reflect.Map:
 		if value.Kind() != reflect.Map {
-			return reflect.Value{}, fmt.Errorf("invalid value for map target %s", value.Kind().String())
+			return reflect.Value{}, conflict()
 		}
 		// New must also be the same type
 		if eMap, eOk := existing.Interface().(map[string]reflect.Value); eOk {
@@ -353,7 +968,7 @@ func (c *cs) replaceOrMergeValues(existing reflect.Value, value reflect.Value) (
 					if el, elOk := eMap[k]; elOk {
 						// map contains value, we merge
 						var err error
-						v, err = c.replaceOrMergeValues(el, v)
+						v, err = c.replaceOrMergeValues(joinKey(key, k), source, el, v)
 						if err != nil {
 							return reflect.Value{}, err
 						}
@@ -371,65 +986,96 @@ func (c *cs) replaceOrMergeValues(existing reflect.Value, value reflect.Value) (
 	}
 }
 
-func (c *cs) withCleanData(callback func() error) error {
-	c.lock.RLock()
+func (c *cs) withCleanData(ctx context.Context, callback func() error) error {
 
-	if c.dirty {
-		c.lock.RUnlock()
+	for {
+		c.lock.RLock()
 
-		// Build data from sources
-		err := c.loadData()
+		if !c.dirty {
+			defer c.lock.RUnlock()
+			return callback()
+		}
 
-		if err != nil {
+		c.lock.RUnlock()
+
+		// Build data from sources, or wait for a load already in progress, then check again as sources may have
+		// changed, or the load failed, in the meantime
+		if err := c.loadData(ctx); err != nil {
 			return err
 		}
-
-		// Re-establish the read lock
-		c.lock.RLock()
 	}
+}
 
-	defer c.lock.RUnlock()
+// generation returns a counter which changes each time the sources or data of the cs change. Data is loaded first
+// if required, so the returned generation reflects the data subsequent reads will see
+func (c *cs) generation(ctx context.Context) (uint64, error) {
+	var gen uint64
+	err := c.withCleanData(ctx, func() error {
+		gen = c.gen
+		return nil
+	})
+	return gen, err
+}
 
-	return callback()
+func (c *cs) Sub(prefix string) Config {
+	return newSubConfig(c, prefix)
+}
 
+func (c *cs) CacheStats() CacheStats {
+	// The cs does not cache values itself
+	return CacheStats{}
 }
 
-func (c *cs) read(fullKey, key string, data map[string]reflect.Value, into any) error {
+func (c *cs) read(rs *readState, fullKey, key string, data map[string]reflect.Value, into any) error {
 	parts := strings.SplitN(key, ".", 2)
 	thisKey := parts[0]
 	if thisKey == "" {
 		// Special case for root of the cs
-		return c.fromValue("", reflect.ValueOf(c.root), into)
+		rs.present = true
+		return c.fromValue(rs, "", reflect.ValueOf(c.root), into)
 	}
 	if tmp, ok := data[thisKey]; ok {
 		if len(parts) == 1 {
-			return c.fromValue(fullKey, tmp, into)
+			rs.present = true
+			return c.fromValue(rs, fullKey, tmp, into)
 		} else if data, ok = tmp.Interface().(map[string]reflect.Value); ok {
-			return c.read(fullKey, parts[1], data, into)
+			return c.read(rs, fullKey, parts[1], data, into)
 		}
-		return fmt.Errorf("invalid type for key %s", thisKey)
+		return &KeyError{Key: fullKey, Err: fmt.Errorf("value at %s is not a map", thisKey)}
 	}
-	// We still populate the value in the case it is a struct and we can lookup keys based on fields
-	return c.fromValue(fullKey, reflect.New(typeMapStringReflectValue).Elem(), into)
+	// A missing key is populated with no value, so structs still apply defaults and late binding sources, and missing
+	// values can be told apart from empty ones
+	return c.fromValue(rs, fullKey, reflect.Value{}, into)
+}
+
+func (c *cs) Read(key string, into any, opts ...Option) error {
+	return c.ReadContext(context.Background(), key, into, opts...)
 }
 
-func (c *cs) Read(key string, into any) error {
+func (c *cs) ReadContext(ctx context.Context, key string, into any, opts ...Option) error {
 	if reflect.ValueOf(into).Kind() != reflect.Ptr {
 		return errors.New("into must be a pointer")
 	}
-	return c.withCleanData(func() error {
-		return c.read(key, key, c.root, into)
+	return c.withCleanData(ctx, func() error {
+		rs := newReadState(ctx, c.opts.withOptions(opts...))
+		err := c.read(rs, key, key, c.root, into)
+		if rs.opts.found != nil {
+			*rs.opts.found = rs.present || rs.lateBound > 0
+		}
+		return err
 	})
 }
 
-func (c *cs) MustRead(key string, into any) {
-	if err := c.Read(key, into); err != nil {
+func (c *cs) MustRead(key string, into any, opts ...Option) {
+	if err := c.Read(key, into, opts...); err != nil {
 		panic(err)
 	}
 }
 
-func newConfig() Config {
+func newConfig(opts ...Option) *cs {
 	return &cs{
-		root: map[string]reflect.Value{},
+		root:          map[string]reflect.Value{},
+		subscriptions: map[int]*subscription{},
+		opts:          readOptions{}.withOptions(opts...),
 	}
 }
@@ -13,6 +13,50 @@ type SimpleConfig struct {
 	Value3 bool
 }
 
+type BaseConfig struct {
+	Name string
+}
+
+type TaggedConfig struct {
+	BaseConfig
+	DBHost   string   `cs:"db_host"`
+	DBPort   int      `cs:"db_port,default=5432"`
+	Hosts    []string `cs:",default=a,b"`
+	User     string   `json:"username"`
+	Password string   `yaml:"pass,omitempty"`
+	Ignored  string   `cs:"-"`
+	Options  string   `cs:"options,omitempty"`
+}
+
+type SquashedPtrConfig struct {
+	*BaseConfig `cs:",squash"`
+	Host        string
+}
+
+type baseConfig struct {
+	Name string
+}
+
+type namedConfig struct {
+	Name string
+}
+
+type UnexportedEmbedConfig struct {
+	baseConfig
+	namedConfig `cs:"named"`
+	Port        int
+}
+
+type RequiredConfig struct {
+	Value1 string `cs:",required"`
+}
+
+type ListConfig struct {
+	Brokers []string
+	Ports   [2]int
+	Servers []SimpleConfig
+}
+
 func TestConfig(t *testing.T) {
 
 	a := assert.New(t)
@@ -172,6 +216,188 @@ func TestConfig(t *testing.T) {
 				a.Equal(3, got1)
 			},
 		},
+		"lists": {
+			arrange: func(c cs.Config) {
+				c.AddSource(func() (string, any, error) {
+					return key1, map[string]any{
+						"brokers": []any{"a", "b"},
+						"ports":   []int{80, 443},
+						"servers": []any{
+							map[string]any{
+								value1: "a",
+								value2: 1,
+							},
+							&SimpleConfig{
+								Value1: "b",
+								Value2: 2,
+								Value3: true,
+							},
+						},
+					}, nil
+				})
+			},
+			assert: func(c cs.Config) {
+				var got1 []string
+				c.MustRead("key1.brokers", &got1)
+				a.Equal([]string{"a", "b"}, got1)
+
+				var got2 [2]int
+				c.MustRead("key1.ports", &got2)
+				a.Equal([2]int{80, 443}, got2)
+
+				got3 := &ListConfig{}
+				c.MustRead(key1, got3)
+				a.Equal(&ListConfig{
+					Brokers: []string{"a", "b"},
+					Ports:   [2]int{80, 443},
+					Servers: []SimpleConfig{
+						{Value1: "a", Value2: 1},
+						{Value1: "b", Value2: 2, Value3: true},
+					},
+				}, got3)
+
+				got4 := map[string]any{}
+				c.MustRead(key1, &got4)
+				a.Equal([]any{"a", "b"}, got4["brokers"])
+				a.Equal([]any{80, 443}, got4["ports"])
+				a.Equal([]any{
+					map[string]any{value1: "a", value2: 1},
+					map[string]any{value1: "b", value2: 2, "value3": true},
+				}, got4["servers"])
+
+				var got5 [1]int
+				a.Error(c.Read("key1.ports", &got5))
+			},
+		},
+		"list overrides": {
+			arrange: func(c cs.Config) {
+				c.AddSource(func() (string, any, error) {
+					return key1, []string{"a", "b", "c"}, nil
+				})
+				c.AddSource(func() (string, any, error) {
+					return key1, []string{"d"}, nil
+				})
+				c.AddSource(func() (string, any, error) {
+					return key2, []string{"a"}, nil
+				})
+				c.AddLateBindingSource(func(key string) (any, error) {
+					if key == key2 {
+						return "e, f", nil
+					}
+					return nil, nil
+				})
+			},
+			assert: func(c cs.Config) {
+				var got1 []string
+				var got2 []string
+				c.MustRead(key1, &got1)
+				c.MustRead(key2, &got2)
+				a.Equal([]string{"d"}, got1)
+				a.Equal([]string{"e", "f"}, got2)
+			},
+		},
+		"struct tags": {
+			arrange: func(c cs.Config) {
+				c.AddSource(func() (string, any, error) {
+					return key1, map[string]any{
+						"name":     "a",
+						"db_host":  "localhost",
+						"username": "user",
+						"pass":     "secret",
+						"ignored":  "b",
+					}, nil
+				})
+				c.AddSource(func() (string, any, error) {
+					return key2, &TaggedConfig{
+						BaseConfig: BaseConfig{Name: "b"},
+						DBHost:     "remote",
+						Ignored:    "c",
+					}, nil
+				})
+			},
+			assert: func(c cs.Config) {
+				got1 := &TaggedConfig{}
+				c.MustRead(key1, got1)
+				a.Equal(&TaggedConfig{
+					BaseConfig: BaseConfig{Name: "a"},
+					DBHost:     "localhost",
+					DBPort:     5432,
+					Hosts:      []string{"a", "b"},
+					User:       "user",
+					Password:   "secret",
+				}, got1)
+
+				got2 := map[string]any{}
+				c.MustRead(key2, &got2)
+				a.Equal(map[string]any{
+					"name":     "b",
+					"db_host":  "remote",
+					"db_port":  0,
+					"hosts":    []any{},
+					"username": "",
+				}, got2)
+
+				a.EqualError(c.Read(key1, &RequiredConfig{}), "key key1.value1: key not found")
+			},
+		},
+		"squashed pointers": {
+			arrange: func(c cs.Config) {
+				c.AddSource(func() (string, any, error) {
+					return key1, map[string]any{
+						"host": "a",
+						"name": "b",
+					}, nil
+				})
+				c.AddSource(func() (string, any, error) {
+					return key2, map[string]any{
+						"host": "c",
+					}, nil
+				})
+			},
+			assert: func(c cs.Config) {
+				got1 := &SquashedPtrConfig{}
+				a.NoError(c.Read(key1, got1, cs.WithStrictKeys(true)))
+				a.Equal(&SquashedPtrConfig{
+					BaseConfig: &BaseConfig{Name: "b"},
+					Host:       "a",
+				}, got1)
+
+				// Squashed pointers without any configured fields stay nil
+				got2 := &SquashedPtrConfig{}
+				a.NoError(c.Read(key2, got2))
+				a.Equal(&SquashedPtrConfig{Host: "c"}, got2)
+			},
+		},
+		"unexported embedded structs": {
+			arrange: func(c cs.Config) {
+				c.AddSource(func() (string, any, error) {
+					return key1, UnexportedEmbedConfig{
+						baseConfig:  baseConfig{Name: "a"},
+						namedConfig: namedConfig{Name: "b"},
+						Port:        8080,
+					}, nil
+				})
+			},
+			assert: func(c cs.Config) {
+				got1 := map[string]any{}
+				c.MustRead(key1, &got1)
+				a.Equal(map[string]any{
+					"name": "a",
+					"named": map[string]any{
+						"name": "b",
+					},
+					"port": 8080,
+				}, got1)
+
+				got2 := &UnexportedEmbedConfig{}
+				c.MustRead(key1, got2)
+				a.Equal(&UnexportedEmbedConfig{
+					baseConfig:  baseConfig{Name: "a"},
+					namedConfig: namedConfig{Name: "b"},
+					Port:        8080,
+				}, got2)
+			},
+		},
 	}
 
 	for k, v := range cases {
@@ -184,3 +410,109 @@ func TestConfig(t *testing.T) {
 		})
 	}
 }
+
+type manualWatchable struct {
+	notify func()
+}
+
+func (m *manualWatchable) Watch(notify func()) func() {
+	m.notify = notify
+	return func() {
+		m.notify = nil
+	}
+}
+
+func TestWatchableSource(t *testing.T) {
+
+	a := assert.New(t)
+
+	unit := cs.NewConfig()
+	defer unit.Close()
+
+	data := map[string]any{
+		"value1": "a",
+		"value2": 1,
+	}
+	w := &manualWatchable{}
+
+	unit.AddWatchableSource(func() (string, any, error) {
+		return "key1", data, nil
+	}, w)
+
+	type change struct {
+		old any
+		new any
+	}
+	var changes1, changes2 []change
+	unit.Subscribe("key1.value1", func(old, new any) {
+		changes1 = append(changes1, change{old: old, new: new})
+	})
+	unsubscribe := unit.Subscribe("key1.value2", func(old, new any) {
+		changes2 = append(changes2, change{old: old, new: new})
+	})
+
+	var got string
+	unit.MustRead("key1.value1", &got)
+	a.Equal("a", got)
+
+	data = map[string]any{
+		"value1": "b",
+		"value2": 1,
+	}
+	w.notify()
+
+	unit.MustRead("key1.value1", &got)
+	a.Equal("b", got)
+	a.Equal([]change{{old: "a", new: "b"}}, changes1)
+	a.Empty(changes2)
+
+	unsubscribe()
+
+	data = map[string]any{
+		"value1": "b",
+		"value2": 2,
+	}
+	w.notify()
+	a.Len(changes1, 1)
+	a.Empty(changes2)
+
+	unit.Close()
+	a.Nil(w.notify)
+}
+
+func TestWatchableLateBindingProvider(t *testing.T) {
+
+	a := assert.New(t)
+
+	unit := cs.NewConfig()
+	defer unit.Close()
+
+	value := "a"
+	w := &manualWatchable{}
+
+	unit.AddLateBindingProvider(cs.NewWatchableLateBindingProvider("lb", func(key string) (any, error) {
+		if key == "key1" {
+			return value, nil
+		}
+		return nil, nil
+	}, w))
+
+	var got string
+	unit.MustRead("key1", &got)
+	a.Equal("a", got)
+
+	// Reads are served from the cache until the watchable reports a change
+	value = "b"
+	got = ""
+	unit.MustRead("key1", &got)
+	a.Equal("a", got)
+
+	w.notify()
+
+	got = ""
+	unit.MustRead("key1", &got)
+	a.Equal("b", got)
+
+	a.NoError(unit.RemoveLateBindingSource("lb"))
+	a.Nil(w.notify)
+}
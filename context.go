@@ -0,0 +1,145 @@
+package cs
+
+import (
+	"context"
+	"fmt"
+)
+
+// ContextSource is a Source which receives the context of the read which triggered loading. It should return
+// promptly once the context is done
+type ContextSource func(ctx context.Context) (string, any, error)
+
+// ContextLateBindingSource is a LateBindingSource which receives the context of the read
+type ContextLateBindingSource func(ctx context.Context, key string) (any, error)
+
+type contextSourceProvider struct {
+	name string
+	src  ContextSource
+}
+
+func (p *contextSourceProvider) Name() string {
+	return p.name
+}
+
+func (p *contextSourceProvider) Load(ctx context.Context) (string, any, error) {
+	return p.src(ctx)
+}
+
+type contextLateBindingProvider struct {
+	name string
+	src  ContextLateBindingSource
+}
+
+func (p *contextLateBindingProvider) Name() string {
+	return p.name
+}
+
+func (p *contextLateBindingProvider) Lookup(ctx context.Context, key string) (any, error) {
+	return p.src(ctx, key)
+}
+
+// NewContextSourceProvider adapts a ContextSource to a SourceProvider with the given name
+func NewContextSourceProvider(name string, src ContextSource) SourceProvider {
+	return &contextSourceProvider{
+		name: name,
+		src:  src,
+	}
+}
+
+// NewContextLateBindingProvider adapts a ContextLateBindingSource to a LateBindingProvider with the given name
+func NewContextLateBindingProvider(name string, src ContextLateBindingSource) LateBindingProvider {
+	return &contextLateBindingProvider{
+		name: name,
+		src:  src,
+	}
+}
+
+// TimeoutError is returned when a source or late binding source doesn't complete before the context of a read is
+// done. It wraps the context error, so errors.Is(err, context.DeadlineExceeded) and
+// errors.Is(err, context.Canceled) can be used
+type TimeoutError struct {
+	// Source is the name of the source
+	Source string
+	// Key is the key being looked up, for late binding sources
+	Key string
+	Err error
+}
+
+func (e *TimeoutError) Error() string {
+	if e.Key != "" {
+		return fmt.Sprintf("late binding source %s did not complete lookup of key %s: %s", e.Source, e.Key, e.Err.Error())
+	}
+	return fmt.Sprintf("source %s did not complete: %s", e.Source, e.Err.Error())
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// readState holds the state of a single read
+type readState struct {
+	ctx  context.Context
+	opts readOptions
+	// lateBound counts the values supplied by late binding sources, so reads can tell whether keys which aren't in the
+	// root were populated
+	lateBound int
+	// present is set when the key being read has a value in the root
+	present bool
+}
+
+func newReadState(ctx context.Context, opts readOptions) *readState {
+	return &readState{
+		ctx:  ctx,
+		opts: opts,
+	}
+}
+
+type loadResult struct {
+	key string
+	val any
+	err error
+}
+
+// loadWithContext loads a source, returning a *TimeoutError if the context is done first. Sources which ignore the
+// context are abandoned rather than waited for
+func loadWithContext(ctx context.Context, p SourceProvider) (string, any, error) {
+	if err := ctx.Err(); err != nil {
+		return "", nil, &TimeoutError{Source: p.Name(), Err: err}
+	}
+	if ctx.Done() == nil {
+		// The context can never be done, so there is no need to wait in the background
+		return p.Load(ctx)
+	}
+	ch := make(chan loadResult, 1)
+	go func() {
+		key, val, err := p.Load(ctx)
+		ch <- loadResult{key: key, val: val, err: err}
+	}()
+	select {
+	case res := <-ch:
+		return res.key, res.val, res.err
+	case <-ctx.Done():
+		return "", nil, &TimeoutError{Source: p.Name(), Err: ctx.Err()}
+	}
+}
+
+// lookupWithContext looks up a key from a late binding source, returning a *TimeoutError if the context is done first
+func lookupWithContext(ctx context.Context, p LateBindingProvider, key string) (any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, &TimeoutError{Source: p.Name(), Key: key, Err: err}
+	}
+	if ctx.Done() == nil {
+		return p.Lookup(ctx, key)
+	}
+	ch := make(chan loadResult, 1)
+	go func() {
+		val, err := p.Lookup(ctx, key)
+		ch <- loadResult{val: val, err: err}
+	}()
+	select {
+	case res := <-ch:
+		return res.val, res.err
+	case <-ctx.Done():
+		return nil, &TimeoutError{Source: p.Name(), Key: key, Err: ctx.Err()}
+	}
+}
@@ -0,0 +1,131 @@
+package cs_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/activatedio/cs"
+	"github.com/stretchr/testify/assert"
+)
+
+type ctxKey struct{}
+
+func TestReadContext(t *testing.T) {
+
+	a := assert.New(t)
+
+	unit := cs.NewConfig()
+
+	release := make(chan struct{})
+	defer close(release)
+
+	unit.AddSourceProvider(cs.NewContextSourceProvider("context", func(ctx context.Context) (string, any, error) {
+		return "key1", ctx.Value(ctxKey{}), nil
+	}))
+	unit.AddSourceProvider(cs.NewSourceProvider("hanging", func() (string, any, error) {
+		<-release
+		return "key2", "b", nil
+	}))
+
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), ctxKey{}, "a"), 20*time.Millisecond)
+	defer cancel()
+
+	var got string
+	err := unit.ReadContext(ctx, "key1", &got)
+
+	var te *cs.TimeoutError
+	a.ErrorAs(err, &te)
+	a.Equal("hanging", te.Source)
+	a.ErrorIs(err, context.DeadlineExceeded)
+	a.EqualError(err, "source hanging did not complete: context deadline exceeded")
+
+	a.NoError(unit.RemoveSource("hanging"))
+
+	ctx, cancel = context.WithTimeout(context.WithValue(context.Background(), ctxKey{}, "a"), time.Second)
+	defer cancel()
+
+	a.NoError(unit.ReadContext(ctx, "key1", &got))
+	a.Equal("a", got)
+}
+
+func TestReadContext_LateBinding(t *testing.T) {
+
+	a := assert.New(t)
+
+	unit := cs.NewConfig()
+	unit.AddLateBindingProvider(cs.NewContextLateBindingProvider("slow", func(ctx context.Context, key string) (any, error) {
+		if key != "key1" {
+			return nil, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+			return "a", nil
+		}
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var got string
+	err := unit.ReadContext(ctx, "key1", &got)
+
+	var te *cs.TimeoutError
+	a.ErrorAs(err, &te)
+	a.Equal("slow", te.Source)
+	a.Equal("key1", te.Key)
+	a.ErrorIs(err, context.Canceled)
+}
+
+func TestReadContext_WaitingForLoad(t *testing.T) {
+
+	a := assert.New(t)
+
+	unit := cs.NewConfig()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	unit.AddSourceProvider(cs.NewSourceProvider("blocking", func() (string, any, error) {
+		close(started)
+		<-release
+		return "key1", "a", nil
+	}))
+
+	// A read without a deadline starts the load, which blocks
+	first := make(chan error, 1)
+	go func() {
+		var got string
+		first <- unit.Read("key1", &got)
+	}()
+
+	<-started
+
+	// A second read waiting for the load in progress gives up at its own deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	begin := time.Now()
+	var got string
+	err := unit.ReadContext(ctx, "key1", &got)
+
+	var te *cs.TimeoutError
+	a.ErrorAs(err, &te)
+	a.Equal("blocking", te.Source)
+	a.ErrorIs(err, context.DeadlineExceeded)
+	a.Less(time.Since(begin), time.Second)
+
+	close(release)
+
+	select {
+	case err = <-first:
+		a.NoError(err)
+	case <-time.After(5 * time.Second):
+		a.Fail("timed out waiting for first read")
+	}
+
+	a.NoError(unit.ReadContext(context.Background(), "key1", &got))
+	a.Equal("a", got)
+}
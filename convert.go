@@ -0,0 +1,121 @@
+package cs
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+// strictCastAndSet converts a value to the type of its destination, failing if the conversion would lose information
+func strictCastAndSet(dest, src reflect.Value) error {
+
+	raw := src.Interface()
+
+	switch dest.Kind() {
+	case reflect.String:
+		s, err := cast.ToStringE(raw)
+		if err != nil {
+			return err
+		}
+		dest.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strictInt(src)
+		if err != nil {
+			return err
+		}
+		if dest.OverflowInt(i) {
+			return fmt.Errorf("value %d overflows %s", i, dest.Kind().String())
+		}
+		dest.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strictUint(src)
+		if err != nil {
+			return err
+		}
+		if dest.OverflowUint(u) {
+			return fmt.Errorf("value %d overflows %s", u, dest.Kind().String())
+		}
+		dest.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := cast.ToFloat64E(trimString(raw))
+		if err != nil {
+			return err
+		}
+		if dest.OverflowFloat(f) {
+			return fmt.Errorf("value %v overflows %s", f, dest.Kind().String())
+		}
+		dest.SetFloat(f)
+	case reflect.Bool:
+		b, err := cast.ToBoolE(trimString(raw))
+		if err != nil {
+			return err
+		}
+		dest.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported type %s", dest.Type().String())
+	}
+
+	return nil
+}
+
+func strictInt(src reflect.Value) (int64, error) {
+	switch src.Kind() {
+	case reflect.String:
+		return strconv.ParseInt(strings.TrimSpace(src.String()), 10, 64)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return src.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if src.Uint() > math.MaxInt64 {
+			return 0, fmt.Errorf("value %d overflows int64", src.Uint())
+		}
+		return int64(src.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		f := src.Float()
+		if f != math.Trunc(f) {
+			return 0, fmt.Errorf("value %v has a fractional part", f)
+		}
+		if f < math.MinInt64 || f >= math.MaxInt64 {
+			return 0, fmt.Errorf("value %v overflows int64", f)
+		}
+		return int64(f), nil
+	default:
+		return cast.ToInt64E(src.Interface())
+	}
+}
+
+func strictUint(src reflect.Value) (uint64, error) {
+	switch src.Kind() {
+	case reflect.String:
+		return strconv.ParseUint(strings.TrimSpace(src.String()), 10, 64)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if src.Int() < 0 {
+			return 0, fmt.Errorf("value %d is negative", src.Int())
+		}
+		return uint64(src.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return src.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		f := src.Float()
+		if f != math.Trunc(f) {
+			return 0, fmt.Errorf("value %v has a fractional part", f)
+		}
+		if f < 0 || f >= math.MaxUint64 {
+			return 0, fmt.Errorf("value %v overflows uint64", f)
+		}
+		return uint64(f), nil
+	default:
+		return cast.ToUint64E(src.Interface())
+	}
+}
+
+// trimString trims surrounding space from strings, which commonly appears in values from environment variables
+func trimString(v any) any {
+	if s, ok := v.(string); ok {
+		return strings.TrimSpace(s)
+	}
+	return v
+}
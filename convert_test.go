@@ -0,0 +1,130 @@
+package cs_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/activatedio/cs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStrictConversion(t *testing.T) {
+
+	type Level int8
+
+	cases := map[string]struct {
+		value    any
+		into     func() any
+		expected any
+		err      string
+	}{
+		"int from string": {
+			value:    " 42 ",
+			into:     func() any { return new(int) },
+			expected: 42,
+		},
+		"int from invalid string": {
+			value: "abc",
+			into:  func() any { return new(int) },
+			err:   `key key1: cannot convert string "abc" from source env to int: strconv.ParseInt: parsing "abc": invalid syntax`,
+		},
+		"int8 overflow": {
+			value: 300,
+			into:  func() any { return new(int8) },
+			err:   "key key1: cannot convert int 300 from source env to int8: value 300 overflows int8",
+		},
+		"named int8": {
+			value:    "-12",
+			into:     func() any { return new(Level) },
+			expected: Level(-12),
+		},
+		"int from fraction": {
+			value: 1.5,
+			into:  func() any { return new(int) },
+			err:   "key key1: cannot convert float64 1.5 from source env to int: value 1.5 has a fractional part",
+		},
+		"int from whole float": {
+			value:    2.0,
+			into:     func() any { return new(int) },
+			expected: 2,
+		},
+		"uint from negative": {
+			value: -1,
+			into:  func() any { return new(uint) },
+			err:   "key key1: cannot convert int -1 from source env to uint: value -1 is negative",
+		},
+		"uint16 overflow": {
+			value: "70000",
+			into:  func() any { return new(uint16) },
+			err:   `key key1: cannot convert string "70000" from source env to uint16: value 70000 overflows uint16`,
+		},
+		"bool from typo": {
+			value: "ture",
+			into:  func() any { return new(bool) },
+			err:   `key key1: cannot convert string "ture" from source env to bool: strconv.ParseBool: parsing "ture": invalid syntax`,
+		},
+		"float32 overflow": {
+			value: 1e40,
+			into:  func() any { return new(float32) },
+			err:   "key key1: cannot convert float64 1e+40 from source env to float32: value 1e+40 overflows float32",
+		},
+	}
+
+	for k, v := range cases {
+		t.Run(k, func(t *testing.T) {
+
+			a := assert.New(t)
+
+			unit := cs.NewConfig(cs.WithStrictConversion(true))
+			unit.AddLateBindingProvider(cs.NewLateBindingProvider("env", func(key string) (any, error) {
+				if key == "key1" {
+					return v.value, nil
+				}
+				return nil, nil
+			}))
+
+			into := v.into()
+			err := unit.Read("key1", into)
+			if v.err != "" {
+				var ce *cs.ConversionError
+				a.ErrorAs(err, &ce)
+				a.Equal("key1", ce.Key)
+				a.Equal(v.value, ce.Value)
+				a.EqualError(err, v.err)
+			} else {
+				a.NoError(err)
+				a.Equal(v.expected, reflect.ValueOf(into).Elem().Interface())
+			}
+		})
+	}
+}
+
+func TestStrictConversion_PerRead(t *testing.T) {
+
+	a := assert.New(t)
+
+	unit := cs.NewConfig()
+	unit.AddSource(func() (string, any, error) {
+		return "server", map[string]any{"port": "abc"}, nil
+	})
+
+	var got int
+	a.NoError(unit.Read("server.port", &got))
+	a.Equal(0, got)
+
+	var ce *cs.ConversionError
+	a.ErrorAs(unit.Read("server.port", &got, cs.WithStrictConversion(true)), &ce)
+	a.Equal("source[0]", ce.Source)
+	a.Equal("server.port", ce.Key)
+
+	unit = cs.NewConfig(cs.WithStrictConversion(true))
+	unit.AddSource(func() (string, any, error) {
+		return "server", map[string]any{"port": "abc"}, nil
+	})
+
+	a.Error(unit.Read("server.port", &got))
+	a.NoError(unit.Read("server.port", &got, cs.WithStrictConversion(false)))
+
+	_, err := cs.Get[int](unit, "server.port")
+	a.ErrorAs(err, &ce)
+}
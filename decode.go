@@ -0,0 +1,257 @@
+package cs
+
+import (
+	"fmt"
+	"io/fs"
+	"math"
+	"math/big"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+// decoder converts a raw value from a source or late binding source to a value of the type it is registered for
+type decoder func(opts readOptions, raw any) (any, error)
+
+// wellKnownDecoders holds decoders for types which can't be populated by kind alone. Values of these types supplied by
+// sources are stored as they are rather than being broken down into maps or lists
+var wellKnownDecoders = map[reflect.Type]decoder{
+	reflect.TypeFor[time.Duration]():  decodeDuration,
+	reflect.TypeFor[time.Time]():      decodeTime,
+	reflect.TypeFor[url.URL]():        decodeURL,
+	reflect.TypeFor[*url.URL]():       decodeURLPtr,
+	reflect.TypeFor[net.IP]():         decodeIP,
+	reflect.TypeFor[net.IPNet]():      decodeIPNet,
+	reflect.TypeFor[*net.IPNet]():     decodeIPNetPtr,
+	reflect.TypeFor[netip.Addr]():     decodeAddr,
+	reflect.TypeFor[netip.Prefix]():   decodePrefix,
+	reflect.TypeFor[*regexp.Regexp](): decodeRegexp,
+	reflect.TypeFor[big.Int]():        decodeBigInt,
+	reflect.TypeFor[*big.Int]():       decodeBigIntPtr,
+	reflect.TypeFor[ByteSize]():       decodeByteSize,
+	reflect.TypeFor[fs.FileMode]():    decodeFileMode,
+}
+
+// defaultTimeLayouts are tried in order when reading times from strings
+var defaultTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	time.DateOnly,
+}
+
+// isWellKnown returns true if values of the type are decoded by a well known decoder
+func isWellKnown(typ reflect.Type) bool {
+	_, ok := wellKnownDecoders[typ]
+	return ok
+}
+
+// populateDecoded populates a destination using a decoder
+func (c *cs) populateDecoded(rs *readState, fullKey string, dest reflect.Value, val reflect.Value, decode decoder) error {
+
+	lbVal, lbName, err := c.lateBindingValue(rs, fullKey)
+	if err != nil {
+		return err
+	}
+	if lbVal != nil {
+		val = reflect.ValueOf(lbVal)
+	}
+
+	if !val.IsValid() {
+		if rs.opts.required[fullKey] {
+			return &KeyError{Key: fullKey, Err: ErrKeyNotFound}
+		}
+		return nil
+	}
+
+	if val.Type() == dest.Type() {
+		// Values such as *big.Int and net.IP are copied, so callers don't share them with sources
+		dest.Set(deepCopy(val))
+		return nil
+	}
+
+	res, err := decode(rs.opts, toInterface(val))
+	if err != nil {
+		return c.conversionError(fullKey, lbName, dest, val, err)
+	}
+	resVal := reflect.ValueOf(res)
+	if !resVal.IsValid() || !resVal.Type().AssignableTo(dest.Type()) {
+		err = fmt.Errorf("decoder returned %T", res)
+		return c.conversionError(fullKey, lbName, dest, val, err)
+	}
+	dest.Set(resVal)
+
+	return nil
+}
+
+// decodeString returns the raw value as a trimmed string, failing for values which aren't strings
+func decodeString(raw any) (string, error) {
+	if s, ok := raw.(string); ok {
+		return strings.TrimSpace(s), nil
+	}
+	return "", fmt.Errorf("expected a string, got %T", raw)
+}
+
+func decodeDuration(_ readOptions, raw any) (any, error) {
+	// Numbers are treated as nanoseconds, strings such as "30s" are parsed
+	return cast.ToDurationE(raw)
+}
+
+func decodeTime(opts readOptions, raw any) (any, error) {
+	switch v := raw.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		// Numbers are unix timestamps in seconds
+		return time.Unix(cast.ToInt64(v), 0).UTC(), nil
+	}
+	s, err := decodeString(raw)
+	if err != nil {
+		return nil, err
+	}
+	layouts := opts.timeLayouts
+	if len(layouts) == 0 {
+		layouts = defaultTimeLayouts
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("time %q does not match any of the layouts %q", s, layouts)
+}
+
+func decodeURLPtr(_ readOptions, raw any) (any, error) {
+	s, err := decodeString(raw)
+	if err != nil {
+		return nil, err
+	}
+	return url.Parse(s)
+}
+
+func decodeURL(opts readOptions, raw any) (any, error) {
+	u, err := decodeURLPtr(opts, raw)
+	if err != nil {
+		return nil, err
+	}
+	return *u.(*url.URL), nil
+}
+
+func decodeIP(_ readOptions, raw any) (any, error) {
+	s, err := decodeString(raw)
+	if err != nil {
+		return nil, err
+	}
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address %q", s)
+	}
+	return ip, nil
+}
+
+func decodeIPNetPtr(_ readOptions, raw any) (any, error) {
+	s, err := decodeString(raw)
+	if err != nil {
+		return nil, err
+	}
+	_, ipNet, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, err
+	}
+	return ipNet, nil
+}
+
+func decodeIPNet(opts readOptions, raw any) (any, error) {
+	ipNet, err := decodeIPNetPtr(opts, raw)
+	if err != nil {
+		return nil, err
+	}
+	return *ipNet.(*net.IPNet), nil
+}
+
+func decodeAddr(_ readOptions, raw any) (any, error) {
+	s, err := decodeString(raw)
+	if err != nil {
+		return nil, err
+	}
+	return netip.ParseAddr(s)
+}
+
+func decodePrefix(_ readOptions, raw any) (any, error) {
+	s, err := decodeString(raw)
+	if err != nil {
+		return nil, err
+	}
+	return netip.ParsePrefix(s)
+}
+
+func decodeRegexp(_ readOptions, raw any) (any, error) {
+	s, err := decodeString(raw)
+	if err != nil {
+		return nil, err
+	}
+	return regexp.Compile(s)
+}
+
+func decodeBigIntPtr(_ readOptions, raw any) (any, error) {
+	switch v := raw.(type) {
+	case int, int8, int16, int32, int64:
+		return big.NewInt(cast.ToInt64(v)), nil
+	case uint, uint8, uint16, uint32, uint64:
+		return new(big.Int).SetUint64(cast.ToUint64(v)), nil
+	}
+	s, err := decodeString(raw)
+	if err != nil {
+		return nil, err
+	}
+	// Base prefixes such as 0x are supported
+	i, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return nil, fmt.Errorf("invalid integer %q", s)
+	}
+	return i, nil
+}
+
+func decodeBigInt(opts readOptions, raw any) (any, error) {
+	i, err := decodeBigIntPtr(opts, raw)
+	if err != nil {
+		return nil, err
+	}
+	return *i.(*big.Int), nil
+}
+
+func decodeByteSize(_ readOptions, raw any) (any, error) {
+	if s, ok := raw.(string); ok {
+		return ParseByteSize(s)
+	}
+	u, err := strictUint(reflect.ValueOf(raw))
+	if err != nil {
+		return nil, err
+	}
+	return ByteSize(u), nil
+}
+
+func decodeFileMode(_ readOptions, raw any) (any, error) {
+	if s, ok := raw.(string); ok {
+		// Strings are octal, with or without a leading 0 or 0o, such as "0644"
+		s = strings.TrimPrefix(strings.TrimSpace(s), "0o")
+		m, err := strconv.ParseUint(s, 8, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid file mode %q", s)
+		}
+		return fs.FileMode(m), nil
+	}
+	u, err := strictUint(reflect.ValueOf(raw))
+	if err != nil {
+		return nil, err
+	}
+	if u > math.MaxUint32 {
+		return nil, fmt.Errorf("invalid file mode %d", u)
+	}
+	return fs.FileMode(u), nil
+}
@@ -0,0 +1,157 @@
+package cs_test
+
+import (
+	"io/fs"
+	"math/big"
+	"net"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/activatedio/cs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWellKnownTypes(t *testing.T) {
+
+	type Config struct {
+		Timeout  time.Duration   `cs:"timeout"`
+		Retries  []time.Duration `cs:"retries"`
+		Started  time.Time       `cs:"started"`
+		Expires  time.Time       `cs:"expires"`
+		Endpoint url.URL         `cs:"endpoint"`
+		Proxy    *url.URL        `cs:"proxy"`
+		Bind     net.IP          `cs:"bind"`
+		Allowed  *net.IPNet      `cs:"allowed"`
+		Addr     netip.Addr      `cs:"addr"`
+		Prefix   netip.Prefix    `cs:"prefix"`
+		Pattern  *regexp.Regexp  `cs:"pattern"`
+		Supply   big.Int         `cs:"supply"`
+		MaxSize  cs.ByteSize     `cs:"maxSize"`
+		Buffer   cs.ByteSize     `cs:"buffer"`
+		Mode     fs.FileMode     `cs:"mode"`
+		DirMode  fs.FileMode     `cs:"dirMode"`
+	}
+
+	a := assert.New(t)
+
+	started := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+
+	unit := cs.NewConfig()
+	unit.AddSource(func() (string, any, error) {
+		return "app", map[string]any{
+			"timeout":  "30s",
+			"retries":  []any{"1s", "2s"},
+			"started":  started,
+			"expires":  "2025-01-02T03:04:05Z",
+			"endpoint": "https://example.com/api",
+			"proxy":    "http://proxy:3128",
+			"bind":     "10.0.0.1",
+			"allowed":  "10.0.0.0/8",
+			"addr":     "::1",
+			"prefix":   "192.168.0.0/16",
+			"pattern":  "^a+$",
+			"supply":   "123456789012345678901234567890",
+			"maxSize":  "512MiB",
+			"buffer":   4096,
+			"mode":     "0640",
+			"dirMode":  0o755,
+		}, nil
+	})
+	// Late binding sources such as environment variables are decoded in the same way
+	unit.AddLateBindingSource(func(key string) (any, error) {
+		if key == "app.timeout" {
+			return "1m30s", nil
+		}
+		return nil, nil
+	})
+
+	got := Config{}
+	a.NoError(unit.Read("app", &got))
+
+	a.Equal(90*time.Second, got.Timeout)
+	a.Equal([]time.Duration{time.Second, 2 * time.Second}, got.Retries)
+	a.Equal(started, got.Started)
+	a.Equal(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), got.Expires)
+	a.Equal("https://example.com/api", got.Endpoint.String())
+	a.Equal("proxy:3128", got.Proxy.Host)
+	a.Equal("10.0.0.1", got.Bind.String())
+	a.Equal("10.0.0.0/8", got.Allowed.String())
+	a.Equal(netip.MustParseAddr("::1"), got.Addr)
+	a.Equal(netip.MustParsePrefix("192.168.0.0/16"), got.Prefix)
+	a.True(got.Pattern.MatchString("aaa"))
+	a.Equal("123456789012345678901234567890", got.Supply.String())
+	a.Equal(512*cs.Mebibyte, got.MaxSize)
+	a.Equal(cs.ByteSize(4096), got.Buffer)
+	a.Equal(fs.FileMode(0o640), got.Mode)
+	a.Equal(fs.FileMode(0o755), got.DirMode)
+
+	var d time.Duration
+	a.NoError(unit.Read("app.timeout", &d))
+	a.Equal(90*time.Second, d)
+}
+
+func TestWellKnownTypes_Errors(t *testing.T) {
+
+	a := assert.New(t)
+
+	unit := cs.NewConfig()
+	unit.AddSource(func() (string, any, error) {
+		return "app", map[string]any{
+			"timeout": "30 seconds",
+			"started": "01/02/2024",
+		}, nil
+	})
+
+	var d time.Duration
+	err := unit.Read("app.timeout", &d)
+	var ce *cs.ConversionError
+	a.ErrorAs(err, &ce)
+	a.Equal("app.timeout", ce.Key)
+	a.Equal("30 seconds", ce.Value)
+
+	var ts time.Time
+	a.ErrorAs(unit.Read("app.started", &ts), &ce)
+	a.NoError(unit.Read("app.started", &ts, cs.WithTimeLayouts("01/02/2006")))
+	a.Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), ts)
+}
+
+func TestParseByteSize(t *testing.T) {
+
+	cases := map[string]struct {
+		input    string
+		expected cs.ByteSize
+		err      string
+	}{
+		"bytes":         {input: "1024", expected: 1024},
+		"bytes unit":    {input: "10B", expected: 10},
+		"decimal":       {input: "2KB", expected: 2000},
+		"short decimal": {input: "64k", expected: 64000},
+		"binary":        {input: "512MiB", expected: 512 * cs.Mebibyte},
+		"lower case":    {input: "1gib", expected: cs.Gibibyte},
+		"fraction":      {input: "1.5 GiB", expected: 3 * cs.Gibibyte / 2},
+		"space":         {input: " 3 TB ", expected: 3 * cs.Terabyte},
+		"partial byte":  {input: "1.5B", err: `byte size "1.5B" is not a whole number of bytes`},
+		"unknown unit":  {input: "5XB", err: `invalid byte size "5XB": unknown unit "XB"`},
+		"no number":     {input: "MB", err: `invalid byte size "MB"`},
+		"overflow":      {input: "20000PiB", err: `byte size "20000PiB" overflows`},
+	}
+
+	for k, v := range cases {
+		t.Run(k, func(t *testing.T) {
+			a := assert.New(t)
+			got, err := cs.ParseByteSize(v.input)
+			if v.err != "" {
+				a.EqualError(err, v.err)
+			} else {
+				a.NoError(err)
+				a.Equal(v.expected, got)
+			}
+		})
+	}
+
+	assert.Equal(t, "512MiB", (512 * cs.Mebibyte).String())
+	assert.Equal(t, "1500B", cs.ByteSize(1500).String())
+}
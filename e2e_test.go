@@ -2,11 +2,17 @@ package cs_test
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/activatedio/cs"
 	"github.com/activatedio/cs/sources"
+	"github.com/activatedio/cs/sources/dotenv"
+	"github.com/activatedio/cs/sources/ini"
 	"github.com/activatedio/cs/sources/json"
+	"github.com/activatedio/cs/sources/properties"
 	"github.com/activatedio/cs/sources/yaml"
 	"github.com/stretchr/testify/assert"
 )
@@ -94,3 +100,592 @@ func TestEnvironmentVariablesOnly(t *testing.T) {
 	}, got)
 
 }
+
+type Server struct {
+	Host string
+	Port int
+}
+
+type Lists struct {
+	Brokers []string
+	Servers []Server
+}
+
+func TestListSources(t *testing.T) {
+
+	os.Setenv("TEST_LISTS_BROKERS", "broker3,broker4")
+
+	a := assert.New(t)
+
+	unit := cs.NewConfig()
+	unit.AddSource(yaml.NewSourceFromPath("testdata/lists.yaml", "lists"))
+	unit.AddLateBindingSource(sources.NewEnvLateBindingSource("TEST"))
+
+	got := &Lists{}
+
+	unit.MustRead("lists", got)
+
+	a.Equal(&Lists{
+		Brokers: []string{"broker3", "broker4"},
+		Servers: []Server{
+			{Host: "server1", Port: 8080},
+			{Host: "server2", Port: 8081},
+		},
+	}, got)
+}
+
+func TestWatchFile(t *testing.T) {
+
+	a := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	a.NoError(os.WriteFile(path, []byte("hostname: host1\n"), 0o600))
+
+	unit := cs.NewConfig()
+	defer unit.Close()
+
+	unit.AddWatchableSource(yaml.NewSourceFromPath(path, ""), sources.NewFileWatcher(10*time.Millisecond, path))
+
+	changed := make(chan any, 1)
+	unit.Subscribe("hostname", func(_, new any) {
+		changed <- new
+	})
+
+	var got string
+	unit.MustRead("hostname", &got)
+	a.Equal("host1", got)
+
+	a.NoError(os.WriteFile(path, []byte("hostname: host2-changed\n"), 0o600))
+
+	select {
+	case v := <-changed:
+		a.Equal("host2-changed", v)
+	case <-time.After(5 * time.Second):
+		a.Fail("timed out waiting for change")
+	}
+
+	unit.MustRead("hostname", &got)
+	a.Equal("host2-changed", got)
+}
+
+func TestExplain(t *testing.T) {
+
+	a := assert.New(t)
+
+	os.Setenv("TESTEXPLAIN_DISPLAY_NAME", "Display Name Override")
+
+	unit := cs.NewConfig()
+	unit.AddSource(yaml.NewAnnotatedSourceFromPath("testdata/config.yaml", ""))
+	unit.AddSource(json.NewAnnotatedSourceFromPath("testdata/config.json", ""))
+	unit.AddSource(sources.NewSource("displayName", "Display Name Value"))
+	unit.AddLateBindingSource(sources.NewAnnotatedEnvLateBindingSource("TESTEXPLAIN"))
+
+	got, err := unit.Explain("displayName")
+	a.NoError(err)
+	a.Equal(&cs.Explanation{
+		Key:   "displayName",
+		Value: "Display Name Override",
+		Origin: cs.Origin{
+			Source: "env",
+			EnvVar: "TESTEXPLAIN_DISPLAY_NAME",
+		},
+		Chain: []cs.Layer{
+			{
+				Value: "Display Name",
+				Origin: cs.Origin{
+					Source: "yaml",
+					Path:   "testdata/config.yaml",
+					Line:   2,
+				},
+			},
+			{
+				Value: "Display Name Value",
+				Origin: cs.Origin{
+					Source: "source[2]",
+				},
+			},
+			{
+				Value: "Display Name Override",
+				Origin: cs.Origin{
+					Source: "env",
+					EnvVar: "TESTEXPLAIN_DISPLAY_NAME",
+				},
+			},
+		},
+	}, got)
+	a.Equal("env $TESTEXPLAIN_DISPLAY_NAME", got.Origin.String())
+
+	got, err = unit.Explain("database.host")
+	a.NoError(err)
+	a.Equal("dbhost", got.Value)
+	a.Equal("json testdata/config.json", got.Origin.String())
+
+	got, err = unit.Explain("content.title")
+	a.NoError(err)
+	a.Equal("yaml testdata/config.yaml:6", got.Origin.String())
+
+	got, err = unit.Explain("missing")
+	a.NoError(err)
+	a.Nil(got.Value)
+	a.Empty(got.Chain)
+
+	// Sources which aren't annotated return plain values, and are explained by the name of the source
+	_, val, err := yaml.NewSourceFromPath("testdata/config.yaml", "")()
+	a.NoError(err)
+	a.IsType(map[string]any{}, val)
+
+	_, val, err = json.NewSourceFromPath("testdata/config.json", "")()
+	a.NoError(err)
+	a.IsType(map[string]any{}, val)
+
+	val, err = sources.NewEnvLateBindingSource("TESTEXPLAIN")("displayName")
+	a.NoError(err)
+	a.Equal("Display Name Override", val)
+
+	unit = cs.NewConfig()
+	unit.AddSource(yaml.NewSourceFromPath("testdata/config.yaml", ""))
+
+	got, err = unit.Explain("content.title")
+	a.NoError(err)
+	a.Equal("source[0]", got.Origin.String())
+}
+
+func TestDotenv(t *testing.T) {
+
+	a := assert.New(t)
+
+	os.Setenv("TESTDOTENV_HOME", "/home/dev")
+
+	type DB struct {
+		Host     string
+		Port     int
+		User     string
+		URL      string
+		Password string
+		MaxConns int
+	}
+
+	type App struct {
+		DB         DB
+		Name       string
+		NameSuffix string
+		Greeting   string
+		Cert       string
+		Color      string
+		Home       string
+		Empty      string
+	}
+
+	unit := cs.NewConfig()
+	unit.AddLateBindingSource(dotenv.NewLateBindingSourceFromPath("testdata/config.env", "APP"))
+	unit.AddKeyEnumerator(dotenv.NewKeyEnumeratorFromPath("testdata/config.env", "APP"))
+
+	got := App{}
+	a.NoError(unit.Read("", &got))
+
+	a.Equal(App{
+		DB: DB{
+			Host:     "localhost",
+			Port:     5432,
+			User:     "admin # not a comment",
+			URL:      "postgres://admin # not a comment@localhost:5432/app",
+			Password: "${NOT_EXPANDED}",
+			MaxConns: 20,
+		},
+		Name:       "api",
+		NameSuffix: "local",
+		Greeting:   "Hello\t\"World\"\nBye $HOME",
+		Cert:       "-----BEGIN CERT-----\nabc\n-----END CERT-----",
+		Color:      "#fff",
+		Home:       "/home/dev/app",
+	}, got)
+
+	// Multi-word keys resolve to the same names as environment variables
+	var maxConns int
+	a.NoError(unit.Read("db.maxConns", &maxConns))
+	a.Equal(20, maxConns)
+
+	has, err := unit.Has("db")
+	a.NoError(err)
+	a.True(has)
+
+	has, err = unit.Has("empty")
+	a.NoError(err)
+	a.False(has)
+
+	has, err = unit.Has("value")
+	a.NoError(err)
+	a.False(has)
+
+	explanation, err := unit.Explain("db.host")
+	a.NoError(err)
+	a.Equal("localhost", explanation.Value)
+	a.Equal(cs.Origin{Source: "dotenv", Path: "testdata/config.env", Line: 2, EnvVar: "APP_DB_HOST"}, explanation.Origin)
+
+	// The source stores variables under the keys their names map back to
+	path := filepath.Join(t.TempDir(), ".env")
+	a.NoError(os.WriteFile(path, []byte("APP_DB_HOST=localhost\nAPP_DB_MAX_CONNS=20\nAPP_EMPTY=\nOTHER=ignored\n"), 0o600))
+
+	unit = cs.NewConfig()
+	unit.AddSource(dotenv.NewSourceFromPath(path, "APP"))
+
+	all, err := unit.AllSettings()
+	a.NoError(err)
+	a.Equal(map[string]any{
+		"db": map[string]any{
+			"host": "localhost",
+			"max": map[string]any{
+				"conns": "20",
+			},
+		},
+	}, all)
+
+	explanation, err = unit.Explain("db.max.conns")
+	a.NoError(err)
+	a.Equal(cs.Origin{Source: "dotenv", Path: path, Line: 2}, explanation.Origin)
+
+	// Names which nest under another variable can't both be stored
+	unit = cs.NewConfig()
+	unit.AddSource(dotenv.NewSourceFromPath("testdata/config.env", "APP"))
+	a.ErrorContains(unit.Read("", &map[string]any{}), "testdata/config.env: line 9: APP_NAME_SUFFIX conflicts with the value of name")
+
+	// Changes to the file are picked up once the watcher reports them
+	a.NoError(os.WriteFile(path, []byte("DB_HOST=first\n"), 0o600))
+
+	unit = cs.NewConfig()
+	defer unit.Close()
+
+	unit.AddLateBindingProvider(cs.NewWatchableLateBindingProvider("dotenv",
+		dotenv.NewLateBindingSourceFromPath(path, ""), sources.NewFileWatcher(10*time.Millisecond, path)))
+
+	var host string
+	a.NoError(unit.Read("db.host", &host))
+	a.Equal("first", host)
+
+	a.NoError(os.WriteFile(path, []byte("DB_HOST=second-host\n"), 0o600))
+	a.Eventually(func() bool {
+		var host string
+		return unit.Read("db.host", &host) == nil && host == "second-host"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Parse errors include the path and line
+	a.NoError(os.WriteFile(path, []byte("A=1\nB=\"unterminated\n"), 0o600))
+	a.Eventually(func() bool {
+		var host string
+		err := unit.Read("db.host", &host)
+		return err != nil && strings.Contains(err.Error(), path+": line 2: unterminated double quoted value")
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestProperties(t *testing.T) {
+
+	a := assert.New(t)
+
+	unit := cs.NewConfig()
+	unit.AddSource(properties.NewSourceFromPath("testdata/config.properties", "legacy"))
+
+	got := map[string]any{}
+	a.NoError(unit.Read("legacy", &got))
+
+	a.Equal(map[string]any{
+		"app": map[string]any{
+			"name":        "Legacy Service",
+			"description": "A service with a long description",
+		},
+		"db": map[string]any{
+			"host": "dbhost",
+			"port": "5432",
+			"pool": map[string]any{
+				"size": "10",
+			},
+		},
+		"greeting":        "Café 😀\tend",
+		"key with spaces": "spaced",
+		"path":            `C:\data\app`,
+		"brokers":         []any{"broker1", "broker2", "broker3"},
+	}, got)
+
+	var brokers []string
+	a.NoError(unit.Read("legacy.brokers", &brokers))
+	a.Equal([]string{"broker1", "broker2", "broker3"}, brokers)
+
+	explanation, err := unit.Explain("legacy.db.pool.size")
+	a.NoError(err)
+	a.Equal(cs.Origin{Source: "properties", Path: "testdata/config.properties", Line: 8}, explanation.Origin)
+
+	path := filepath.Join(t.TempDir(), "app.properties")
+	a.NoError(os.WriteFile(path, []byte("db=x\ndb.host=y\n"), 0o600))
+
+	unit = cs.NewConfig()
+	unit.AddSource(properties.NewSourceFromPath(path, ""))
+	a.ErrorContains(unit.Read("", &map[string]any{}), path+": line 2: key db.host conflicts with the value of db")
+
+	a.NoError(os.WriteFile(path, []byte("a=\\u00zz\n"), 0o600))
+	a.ErrorContains(unit.Read("", &map[string]any{}), path+`: line 1: invalid unicode escape \u00zz`)
+}
+
+func TestIni(t *testing.T) {
+
+	a := assert.New(t)
+
+	type Pool struct {
+		Size int
+	}
+
+	type DB struct {
+		Host     string
+		Port     int
+		Password string
+		Literal  string
+		Pool     Pool
+	}
+
+	type Servers struct {
+		Host []string
+	}
+
+	type Config struct {
+		Name    string
+		DB      DB
+		Servers Servers
+		Content map[string]string
+	}
+
+	unit := cs.NewConfig()
+	unit.AddSource(ini.NewSourceFromPath("testdata/config.ini", ""))
+
+	got := Config{}
+	a.NoError(unit.Read("", &got))
+
+	a.Equal(Config{
+		Name: "Legacy Service",
+		DB: DB{
+			Host:     "dbhost",
+			Port:     5432,
+			Password: `p;a#ss "quoted"`,
+			Literal:  `no\tescape`,
+			Pool:     Pool{Size: 10},
+		},
+		Servers: Servers{
+			Host: []string{"server1", "server2", "server3"},
+		},
+		Content: map[string]string{
+			"footer": "Some footer",
+			"title":  "Café",
+		},
+	}, got)
+
+	explanation, err := unit.Explain("db.port")
+	a.NoError(err)
+	a.Equal(cs.Origin{Source: "ini", Path: "testdata/config.ini", Line: 6}, explanation.Origin)
+
+	path := filepath.Join(t.TempDir(), "app.ini")
+	a.NoError(os.WriteFile(path, []byte("[db\nhost=x\n"), 0o600))
+
+	unit = cs.NewConfig()
+	unit.AddSource(ini.NewSourceFromPath(path, ""))
+	a.ErrorContains(unit.Read("", &map[string]any{}), path+": line 1: unterminated section header")
+
+	a.NoError(os.WriteFile(path, []byte("[db]\nhost\n"), 0o600))
+	a.ErrorContains(unit.Read("", &map[string]any{}), path+": line 2: expected = or : after key")
+}
+
+// writeMountVersion writes a version of a Kubernetes style ConfigMap mount and atomically swaps ..data to it
+func writeMountVersion(t *testing.T, dir, version string, files map[string]string) {
+
+	a := assert.New(t)
+
+	for name, content := range files {
+		path := filepath.Join(dir, version, name)
+		a.NoError(os.MkdirAll(filepath.Dir(path), 0o700))
+		a.NoError(os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	a.NoError(os.Symlink(version, filepath.Join(dir, "..data_tmp")))
+	a.NoError(os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")))
+
+	for name := range files {
+		top := filepath.Join(dir, strings.Split(name, "/")[0])
+		if _, err := os.Lstat(top); os.IsNotExist(err) {
+			a.NoError(os.Symlink(filepath.Join("..data", strings.Split(name, "/")[0]), top))
+		}
+	}
+}
+
+func TestDirectorySource(t *testing.T) {
+
+	a := assert.New(t)
+
+	dir := t.TempDir()
+	writeMountVersion(t, dir, "..2024_01_01_00_00_00.1", map[string]string{
+		"db.host":         "dbhost\n",
+		"db.port":         "5432\n",
+		"app.yaml":        "name: api\nworkers: 2\n",
+		"features.json":   `{"search": true}`,
+		"tls/ca.crt":      "-----BEGIN CERTIFICATE-----\nabc\n-----END CERTIFICATE-----\n",
+		"nested/level/a":  "b",
+		".dockerconfig":   "{}",
+		"unparsed.config": "x: y\n",
+	})
+
+	unit := cs.NewConfig()
+	defer unit.Close()
+
+	unit.AddWatchableSource(sources.NewDirectorySource(dir, "mount",
+		sources.WithDecoder(".yaml", yaml.Decode),
+		sources.WithDecoder(".json", json.Decode),
+	), sources.NewDirectoryWatcher(10*time.Millisecond, dir))
+
+	got := map[string]any{}
+	a.NoError(unit.Read("mount", &got))
+
+	a.Equal(map[string]any{
+		"db": map[string]any{
+			"host": "dbhost",
+			"port": "5432",
+		},
+		"app": map[string]any{
+			"name":    "api",
+			"workers": 2,
+		},
+		"features": map[string]any{
+			"search": true,
+		},
+		"tls": map[string]any{
+			"ca": map[string]any{
+				"crt": "-----BEGIN CERTIFICATE-----\nabc\n-----END CERTIFICATE-----",
+			},
+		},
+		"nested": map[string]any{
+			"level": map[string]any{
+				"a": "b",
+			},
+		},
+		"dockerconfig": "{}",
+		"unparsed": map[string]any{
+			"config": "x: y",
+		},
+	}, got)
+
+	explanation, err := unit.Explain("mount.db.host")
+	a.NoError(err)
+	a.Equal(cs.Origin{Source: "directory", Path: dir}, explanation.Origin)
+
+	changed := make(chan any, 1)
+	unit.Subscribe("mount.db.host", func(_, new any) {
+		changed <- new
+	})
+
+	// Kubernetes writes a new version of the mount and swaps ..data to it
+	writeMountVersion(t, dir, "..2024_01_02_00_00_00.2", map[string]string{
+		"db.host": "newhost\n",
+		"db.port": "5432\n",
+	})
+
+	select {
+	case v := <-changed:
+		a.Equal("newhost", v)
+	case <-time.After(5 * time.Second):
+		a.Fail("timed out waiting for change")
+	}
+
+	got = map[string]any{}
+	a.NoError(unit.Read("mount", &got))
+	a.Equal(map[string]any{
+		"db": map[string]any{
+			"host": "newhost",
+			"port": "5432",
+		},
+	}, got)
+
+	// Plain directories are read directly
+	plain := t.TempDir()
+	a.NoError(os.WriteFile(filepath.Join(plain, "db.host"), []byte("plainhost\r\n"), 0o600))
+	a.NoError(os.WriteFile(filepath.Join(plain, "db"), []byte("conflict"), 0o600))
+
+	unit = cs.NewConfig()
+	unit.AddSource(sources.NewDirectorySource(plain, ""))
+	a.ErrorContains(unit.Read("", &map[string]any{}), "key db.host conflicts with the value of db")
+
+	a.NoError(os.Remove(filepath.Join(plain, "db")))
+	var host string
+	a.NoError(unit.Read("db.host", &host))
+	a.Equal("plainhost", host)
+}
+
+func TestGlobSources(t *testing.T) {
+
+	a := assert.New(t)
+
+	dir := t.TempDir()
+	a.NoError(os.WriteFile(filepath.Join(dir, "10-base.yaml"), []byte("hostname: base\ndatabase:\n  host: dbhost\n  user: dbuser\n"), 0o600))
+	a.NoError(os.WriteFile(filepath.Join(dir, "20-override.yml"), []byte("database:\n  user: override\n"), 0o600))
+	a.NoError(os.WriteFile(filepath.Join(dir, "30-notes.txt"), []byte("ignored"), 0o600))
+	a.NoError(os.WriteFile(filepath.Join(dir, "05-first.json"), []byte(`{"hostname": "json", "numThreads": 2}`), 0o600))
+
+	unit := cs.NewConfig()
+	defer unit.Close()
+
+	unit.AddSource(json.NewSourceFromGlob(filepath.Join(dir, "*.json"), "app"))
+	unit.AddWatchableSource(yaml.NewSourceFromDirectory(dir, "app"),
+		sources.NewGlobWatcher(10*time.Millisecond, filepath.Join(dir, "*.yaml"), filepath.Join(dir, "*.yml")))
+	unit.AddSource(sources.Optional(yaml.NewSourceFromPath(filepath.Join(dir, "missing.yaml"), "app")))
+
+	got := map[string]any{}
+	a.NoError(unit.Read("app", &got))
+
+	a.Equal(map[string]any{
+		"hostname":   "base",
+		"numThreads": float64(2),
+		"database": map[string]any{
+			"host": "dbhost",
+			"user": "override",
+		},
+	}, got)
+
+	// Each file is a separate layer
+	explanation, err := unit.Explain("app.database.user")
+	a.NoError(err)
+	a.Equal([]cs.Layer{
+		{Value: "dbuser", Origin: cs.Origin{Source: "yaml", Path: filepath.Join(dir, "10-base.yaml"), Line: 4}},
+		{Value: "override", Origin: cs.Origin{Source: "yaml", Path: filepath.Join(dir, "20-override.yml"), Line: 2}},
+	}, explanation.Chain)
+
+	explanation, err = unit.Explain("app.hostname")
+	a.NoError(err)
+	a.Len(explanation.Chain, 2)
+	a.Equal(filepath.Join(dir, "05-first.json"), explanation.Chain[0].Origin.Path)
+
+	changed := make(chan any, 1)
+	unit.Subscribe("app.hostname", func(_, new any) {
+		changed <- new
+	})
+
+	// Added files are picked up on reload
+	a.NoError(os.WriteFile(filepath.Join(dir, "40-local.yaml"), []byte("hostname: local\n"), 0o600))
+
+	select {
+	case v := <-changed:
+		a.Equal("local", v)
+	case <-time.After(5 * time.Second):
+		a.Fail("timed out waiting for change")
+	}
+
+	// Missing files fail unless optional, and a glob matching nothing contributes nothing
+	unit = cs.NewConfig()
+	unit.AddSource(yaml.NewSourceFromPath(filepath.Join(dir, "missing.yaml"), ""))
+	a.ErrorIs(unit.Read("", &map[string]any{}), os.ErrNotExist)
+
+	unit = cs.NewConfig()
+	unit.AddSource(yaml.NewSourceFromGlob(filepath.Join(dir, "*.toml"), ""))
+	unit.AddSource(sources.NewSource("hostname", "only"))
+	var hostname string
+	a.NoError(unit.Read("hostname", &hostname))
+	a.Equal("only", hostname)
+
+	// Errors name the file which failed
+	a.NoError(os.WriteFile(filepath.Join(dir, "50-broken.yaml"), []byte("hostname: [\n"), 0o600))
+	unit = cs.NewConfig()
+	unit.AddSource(yaml.NewSourceFromDirectory(dir, ""))
+	a.ErrorContains(unit.Read("", &map[string]any{}), filepath.Join(dir, "50-broken.yaml")+": yaml:")
+}
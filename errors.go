@@ -0,0 +1,193 @@
+package cs
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrKeyNotFound is wrapped by the *KeyError returned when a required key has no value
+var ErrKeyNotFound = errors.New("key not found")
+
+// KeyError is returned when a key can't be read, for example when a key passes through a value which is not a map
+type KeyError struct {
+	// Key is the full dot separated key
+	Key string
+	Err error
+}
+
+func (e *KeyError) Error() string {
+	return fmt.Sprintf("key %s: %s", e.Key, e.Err.Error())
+}
+
+func (e *KeyError) Unwrap() error {
+	return e.Err
+}
+
+// ConversionError is returned when a value can't be converted to the type of its destination
+type ConversionError struct {
+	// Key is the full dot separated key
+	Key string
+	// Source is the name of the source which supplied the value
+	Source string
+	// Value is the value which couldn't be converted
+	Value any
+	// Expected is the type of the destination
+	Expected reflect.Type
+	// Actual is the type of the value
+	Actual reflect.Type
+	// Err optionally describes why the conversion failed
+	Err error
+}
+
+func (e *ConversionError) Error() string {
+	msg := fmt.Sprintf("key %s: cannot convert %s %s", e.Key, typeName(e.Actual), formatValue(e.Value))
+	if e.Source != "" {
+		msg = fmt.Sprintf("%s from source %s", msg, e.Source)
+	}
+	msg = fmt.Sprintf("%s to %s", msg, typeName(e.Expected))
+	if e.Err != nil {
+		msg = fmt.Sprintf("%s: %s", msg, e.Err.Error())
+	}
+	return msg
+}
+
+func (e *ConversionError) Unwrap() error {
+	return e.Err
+}
+
+// MergeConflictError is returned when a source supplies a value which can't be merged with the value from an earlier
+// source, for example a map where a string was previously defined
+type MergeConflictError struct {
+	// Key is the full dot separated key
+	Key string
+	// Source is the name of the source being merged
+	Source string
+	// Existing is the kind of the existing value, such as string, map or list
+	Existing string
+	// Incoming is the kind of the value from the source
+	Incoming string
+}
+
+func (e *MergeConflictError) Error() string {
+	return fmt.Sprintf("source %s: cannot merge %s into %s at key %s", e.Source, e.Incoming, e.Existing, e.Key)
+}
+
+// UnknownKeyError is returned in strict key mode for a key which matches no field of the struct it is read into
+type UnknownKeyError struct {
+	// Key is the full dot separated key
+	Key string
+	// Suggestion is the full key of the closest field, if one is similar
+	Suggestion string
+}
+
+func (e *UnknownKeyError) Error() string {
+	if e.Suggestion != "" {
+		return fmt.Sprintf("key %s: unknown key, did you mean %s?", e.Key, e.Suggestion)
+	}
+	return fmt.Sprintf("key %s: unknown key", e.Key)
+}
+
+// SourceError is returned when a source or late binding source fails, or returns a value which isn't supported
+type SourceError struct {
+	// Source is the name of the source
+	Source string
+	// Key is the full dot separated key, if the error relates to a single key
+	Key string
+	Err error
+}
+
+func (e *SourceError) Error() string {
+	if e.Key != "" {
+		return fmt.Sprintf("source %s: key %s: %s", e.Source, e.Key, e.Err.Error())
+	}
+	return fmt.Sprintf("source %s: %s", e.Source, e.Err.Error())
+}
+
+func (e *SourceError) Unwrap() error {
+	return e.Err
+}
+
+// AggregateError is returned when a read fails for more than one key. It lists every failure
+type AggregateError struct {
+	Errors []error
+}
+
+func (e *AggregateError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("%d errors occurred: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+func (e *AggregateError) Unwrap() []error {
+	return e.Errors
+}
+
+// joinErrors returns nil for no errors, the error itself for a single error, or an *AggregateError. Nested
+// aggregates are flattened
+func joinErrors(errs []error) error {
+	var res []error
+	for _, err := range errs {
+		if agg, ok := err.(*AggregateError); ok { //nolint:errorlint // only direct aggregates are flattened
+			res = append(res, agg.Errors...)
+		} else if err != nil {
+			res = append(res, err)
+		}
+	}
+	switch len(res) {
+	case 0:
+		return nil
+	case 1:
+		return res[0]
+	default:
+		return &AggregateError{Errors: res}
+	}
+}
+
+// kindName describes the kind of a stored value
+func kindName(val reflect.Value) string {
+	if !val.IsValid() {
+		return "nothing"
+	}
+	switch val.Type() {
+	case typeMapStringReflectValue:
+		return "map"
+	case typeSliceReflectValue:
+		return "list"
+	default:
+		return val.Kind().String()
+	}
+}
+
+// formatValue formats a raw value for an error message, quoting strings so empty and padded values are visible
+func formatValue(v any) string {
+	if s, ok := v.(string); ok {
+		return strconv.Quote(s)
+	}
+	return fmt.Sprint(v)
+}
+
+func typeName(typ reflect.Type) string {
+	if typ == nil {
+		return "nil"
+	}
+	return typ.String()
+}
+
+// sourceError attributes an error to a source. Timeouts and errors which are already attributed are returned as is
+func sourceError(source, key string, err error) error {
+	var te *TimeoutError
+	var se *SourceError
+	if errors.As(err, &te) || errors.As(err, &se) {
+		return err
+	}
+	return &SourceError{
+		Source: source,
+		Key:    key,
+		Err:    err,
+	}
+}
@@ -0,0 +1,133 @@
+package cs_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/activatedio/cs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrors_Merge(t *testing.T) {
+
+	a := assert.New(t)
+
+	unit := cs.NewConfig()
+	unit.AddSourceProvider(cs.NewSourceProvider("base", func() (string, any, error) {
+		return "server", map[string]any{"db": map[string]any{"host": "localhost"}}, nil
+	}))
+	unit.AddSourceProvider(cs.NewSourceProvider("override", func() (string, any, error) {
+		return "server.db.host", map[string]any{"name": "db1"}, nil
+	}))
+
+	var got string
+	err := unit.Read("server.db.host", &got)
+
+	var me *cs.MergeConflictError
+	a.ErrorAs(err, &me)
+	a.Equal("server.db.host", me.Key)
+	a.Equal("override", me.Source)
+	a.Equal("string", me.Existing)
+	a.Equal("map", me.Incoming)
+	a.EqualError(err, "source override: cannot merge map into string at key server.db.host")
+}
+
+func TestErrors_Source(t *testing.T) {
+
+	a := assert.New(t)
+
+	unit := cs.NewConfig()
+	unit.AddSourceProvider(cs.NewSourceProvider("unsupported", func() (string, any, error) {
+		return "server", map[string]any{"handlers": []any{"a", func() {}}}, nil
+	}))
+
+	var got map[string]any
+	err := unit.Read("server", &got)
+
+	var se *cs.SourceError
+	var ke *cs.KeyError
+	a.ErrorAs(err, &se)
+	a.Equal("unsupported", se.Source)
+	a.ErrorAs(err, &ke)
+	a.Equal("server.handlers.1", ke.Key)
+	a.EqualError(err, "source unsupported: key server.handlers.1: unsupported kind func")
+
+	failure := errors.New("connection refused")
+	unit = cs.NewConfig()
+	unit.AddSourceProvider(cs.NewSourceProvider("remote", func() (string, any, error) {
+		return "", nil, failure
+	}))
+
+	err = unit.Read("server", &got)
+	a.ErrorAs(err, &se)
+	a.Equal("remote", se.Source)
+	a.ErrorIs(err, failure)
+}
+
+func TestErrors_Aggregate(t *testing.T) {
+
+	type Server struct {
+		Host  string    `cs:"host,required"`
+		Ports [2]int    `cs:"ports"`
+		Tags  []string  `cs:"tags"`
+		Port  int       `cs:"port,required"`
+		Extra [1]string `cs:"extra"`
+	}
+
+	a := assert.New(t)
+
+	unit := cs.NewConfig()
+	unit.AddSourceProvider(cs.NewSourceProvider("file", func() (string, any, error) {
+		return "server", map[string]any{
+			"ports": []any{1, 2, 3},
+			"tags":  map[string]any{"a": "b"},
+			"extra": "x",
+		}, nil
+	}))
+
+	err := unit.Read("server", &Server{})
+
+	var ae *cs.AggregateError
+	a.ErrorAs(err, &ae)
+	a.Len(ae.Errors, 4)
+
+	var ke *cs.KeyError
+	a.ErrorAs(ae.Errors[0], &ke)
+	a.Equal("server.host", ke.Key)
+
+	var ce *cs.ConversionError
+	a.ErrorAs(ae.Errors[1], &ce)
+	a.Equal("server.ports", ce.Key)
+	a.Equal("file", ce.Source)
+	a.Equal(reflect.TypeFor[[2]int](), ce.Expected)
+	a.Equal(reflect.TypeFor[[]any](), ce.Actual)
+	a.EqualError(ce, "key server.ports: cannot convert []interface {} [1 2 3] from source file to [2]int: too many values: array has length 2 but got 3")
+
+	a.ErrorAs(ae.Errors[2], &ce)
+	a.Equal("server.tags", ce.Key)
+	a.Equal(reflect.TypeFor[map[string]any](), ce.Actual)
+
+	a.ErrorAs(ae.Errors[3], &ke)
+	a.Equal("server.port", ke.Key)
+
+	a.Contains(err.Error(), "4 errors occurred: key server.host: key not found; ")
+}
+
+func TestErrors_KeyPath(t *testing.T) {
+
+	a := assert.New(t)
+
+	unit := cs.NewConfig()
+	unit.AddSource(func() (string, any, error) {
+		return "server.host", "localhost", nil
+	})
+
+	var got string
+	err := unit.Read("server.host.name", &got)
+
+	var ke *cs.KeyError
+	a.ErrorAs(err, &ke)
+	a.Equal("server.host.name", ke.Key)
+	a.EqualError(err, "key server.host.name: value at host is not a map")
+}
@@ -0,0 +1,46 @@
+package cs
+
+// Get reads the value of a key as type T. Values are converted in the same way as Read. Use Global() to read from the
+// global cs object. Options apply in the same way as for Read
+func Get[T any](cfg Config, key string, opts ...Option) (T, error) {
+	var res T
+	err := cfg.Read(key, &res, opts...)
+	return res, err
+}
+
+// Lookup reads the value of a key as type T. The returned bool is false if the read filled no value from either a
+// source or a late binding source, which distinguishes a missing key from one holding the zero value
+func Lookup[T any](cfg Config, key string, opts ...Option) (T, bool, error) {
+	var res T
+	found := false
+	err := cfg.Read(key, &res, append(opts[:len(opts):len(opts)], reportFound(&found))...)
+	if err != nil {
+		return res, false, err
+	}
+	if !found {
+		var zero T
+		return zero, false, nil
+	}
+	return res, true, nil
+}
+
+// GetOr reads the value of a key as type T, returning def if no value is present for the key
+func GetOr[T any](cfg Config, key string, def T, opts ...Option) (T, error) {
+	res, found, err := Lookup[T](cfg, key, opts...)
+	if err != nil {
+		return res, err
+	}
+	if !found {
+		return def, nil
+	}
+	return res, nil
+}
+
+// MustGet reads the value of a key as type T and panics on error
+func MustGet[T any](cfg Config, key string, opts ...Option) T {
+	res, err := Get[T](cfg, key, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
@@ -0,0 +1,112 @@
+package cs_test
+
+import (
+	"testing"
+
+	"github.com/activatedio/cs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGet(t *testing.T) {
+
+	a := assert.New(t)
+
+	unit := cs.NewConfig()
+	unit.AddSource(func() (string, any, error) {
+		return "key1", map[string]any{
+			"port":    "8080",
+			"zero":    0,
+			"enabled": true,
+		}, nil
+	})
+
+	port, err := cs.Get[int](unit, "key1.port")
+	a.NoError(err)
+	a.Equal(8080, port)
+
+	a.Equal("8080", cs.MustGet[string](unit, "key1.port"))
+	a.Equal(map[string]any{
+		"port":    "8080",
+		"zero":    0,
+		"enabled": true,
+	}, cs.MustGet[map[string]any](unit, "key1"))
+
+	zero, found, err := cs.Lookup[int](unit, "key1.zero")
+	a.NoError(err)
+	a.True(found)
+	a.Equal(0, zero)
+
+	_, found, err = cs.Lookup[int](unit, "key1.missing")
+	a.NoError(err)
+	a.False(found)
+
+	got, err := cs.GetOr(unit, "key1.zero", 10)
+	a.NoError(err)
+	a.Equal(0, got)
+
+	got, err = cs.GetOr(unit, "key1.missing", 10)
+	a.NoError(err)
+	a.Equal(10, got)
+
+	unit.AddLateBindingSource(func(key string) (any, error) {
+		if key == "key1.missing" {
+			return "20", nil
+		}
+		return nil, nil
+	})
+
+	got, err = cs.GetOr(unit, "key1.missing", 10)
+	a.NoError(err)
+	a.Equal(20, got)
+
+	a.Panics(func() {
+		cs.MustGet[[1]int](unit, "key1")
+	})
+}
+
+func TestLookup_LateBinding(t *testing.T) {
+
+	a := assert.New(t)
+
+	type DB struct {
+		Host string
+		Port int
+	}
+
+	unit := cs.NewConfig()
+	unit.AddLateBindingSource(func(key string) (any, error) {
+		if key == "db.host" {
+			return "dbhost", nil
+		}
+		return nil, nil
+	})
+
+	// The key is missing from every source, but the read fills a field from the late binding source
+	found, err := unit.Has("db")
+	a.NoError(err)
+	a.False(found)
+
+	db, found, err := cs.Lookup[DB](unit, "db")
+	a.NoError(err)
+	a.True(found)
+	a.Equal(DB{Host: "dbhost"}, db)
+
+	// Reads which fill nothing are not found
+	db, found, err = cs.Lookup[DB](unit, "other")
+	a.NoError(err)
+	a.False(found)
+	a.Equal(DB{}, db)
+
+	got, err := cs.GetOr(unit, "other", DB{Host: "fallback"})
+	a.NoError(err)
+	a.Equal(DB{Host: "fallback"}, got)
+
+	got, err = cs.GetOr(unit, "db", DB{Host: "fallback"})
+	a.NoError(err)
+	a.Equal(DB{Host: "dbhost"}, got)
+
+	// Views report values found through their parent
+	_, found, err = cs.Lookup[string](unit.Sub("db"), "host")
+	a.NoError(err)
+	a.True(found)
+}
@@ -1,13 +1,20 @@
 package cs
 
-// NewConfig returns a new cs object
-func NewConfig() Config {
-	return newCachedConfig()
+import "context"
+
+// NewConfig returns a new cs object. Options apply to every read of the config
+func NewConfig(opts ...Option) Config {
+	return newCachedConfig(opts...)
 }
 
 // cs is the global cs object
 var global = newCachedConfig()
 
+// Global returns the global cs object used by the package level functions
+func Global() Config {
+	return global
+}
+
 // AddSource adds a source to build the root cs object. Sources are invoked in the order they are added.
 // Sources added later take predecent over sources added earlier
 func AddSource(src Source) {
@@ -20,13 +27,89 @@ func AddLateBindingSource(src LateBindingSource) {
 	global.AddLateBindingSource(src)
 }
 
+// AddSourceProvider adds a named source in the same way as AddSource
+func AddSourceProvider(p SourceProvider) {
+	global.AddSourceProvider(p)
+}
+
+// AddLateBindingProvider adds a named late binding source in the same way as AddLateBindingSource
+func AddLateBindingProvider(p LateBindingProvider) {
+	global.AddLateBindingProvider(p)
+}
+
+// RemoveSource removes the source with the given name
+func RemoveSource(name string) error {
+	return global.RemoveSource(name)
+}
+
+// ReplaceSource replaces the source with the given name, keeping its precedence
+func ReplaceSource(name string, p SourceProvider) error {
+	return global.ReplaceSource(name, p)
+}
+
+// RemoveLateBindingSource removes the late binding source with the given name
+func RemoveLateBindingSource(name string) error {
+	return global.RemoveLateBindingSource(name)
+}
+
+// AddKeyEnumerator adds a function listing keys available from late binding sources
+func AddKeyEnumerator(e KeyEnumerator) {
+	global.AddKeyEnumerator(e)
+}
+
+// AddWatchableSource adds a source in the same way as AddSource. Each time the watchable reports a change, all
+// sources are invoked again and subscribers are notified of changed values
+func AddWatchableSource(src Source, w Watchable) {
+	global.AddWatchableSource(src, w)
+}
+
+// Subscribe registers a function which is called with the old and new value of a key when a reload changes it
+func Subscribe(key string, fn func(old, new any)) func() {
+	return global.Subscribe(key, fn)
+}
+
 // Read reads value from the key and assigns it to the provided object, which must be a pointer to a supported value
-// supported values are all primitives and a map
-func Read(key string, into any) error {
-	return global.Read(key, into)
+// supported values are all primitives, maps, structs, slices and arrays
+func Read(key string, into any, opts ...Option) error {
+	return global.Read(key, into, opts...)
+}
+
+// ReadContext reads in the same way as Read, passing the context to sources and late binding sources
+func ReadContext(ctx context.Context, key string, into any, opts ...Option) error {
+	return global.ReadContext(ctx, key, into, opts...)
 }
 
 // MustRead reads and panics on error
-func MustRead(key string, into any) {
-	global.MustRead(key, into)
+func MustRead(key string, into any, opts ...Option) {
+	global.MustRead(key, into, opts...)
+}
+
+// Has returns true if a value is present for the key
+func Has(key string) (bool, error) {
+	return global.Has(key)
+}
+
+// Keys returns the sorted full keys of all values under the prefix which are not maps
+func Keys(prefix string) ([]string, error) {
+	return global.Keys(prefix)
+}
+
+// Children returns the sorted names of the immediate children of the key
+func Children(key string) ([]string, error) {
+	return global.Children(key)
+}
+
+// AllSettings returns all values as nested maps, with late binding sources applied
+func AllSettings() (map[string]any, error) {
+	return global.AllSettings()
+}
+
+// Explain returns the winning value of a key along with every value supplied for it and where each came from
+func Explain(key string) (*Explanation, error) {
+	return global.Explain(key)
+}
+
+// Sub returns a view of the global config scoped to the key prefix
+func Sub(prefix string) Config {
+	return global.Sub(prefix)
 }
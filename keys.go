@@ -0,0 +1,190 @@
+package cs
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+func (c *cs) AddKeyEnumerator(e KeyEnumerator) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.keyEnumerators = append(c.keyEnumerators, e)
+	c.gen++
+}
+
+func (c *cs) Has(key string) (bool, error) {
+	var found bool
+	err := c.withCleanData(context.Background(), func() error {
+		if key == "" || lookupValue(c.root, key).IsValid() {
+			found = true
+			return nil
+		}
+		lbVal, _, err := c.lateBindingValue(newReadState(context.Background(), c.opts), key)
+		if err != nil {
+			return err
+		}
+		if lbVal != nil {
+			found = true
+			return nil
+		}
+		// A key is also present if late binding sources can satisfy any of its children
+		for _, k := range c.enumeratedKeys() {
+			if k == key || strings.HasPrefix(k, key+".") {
+				found = true
+				return nil
+			}
+		}
+		return nil
+	})
+	return found, err
+}
+
+func (c *cs) Keys(prefix string) ([]string, error) {
+	var res []string
+	err := c.withCleanData(context.Background(), func() error {
+		res = c.leafKeys()
+		res = filterPrefix(res, prefix)
+		return nil
+	})
+	return res, err
+}
+
+func (c *cs) Children(key string) ([]string, error) {
+	var res []string
+	err := c.withCleanData(context.Background(), func() error {
+		seen := map[string]bool{}
+		for _, k := range filterPrefix(c.leafKeys(), key) {
+			if k == key {
+				continue
+			}
+			rest := k
+			if key != "" {
+				rest = strings.TrimPrefix(k, key+".")
+			}
+			child, _, _ := strings.Cut(rest, ".")
+			if !seen[child] {
+				seen[child] = true
+				res = append(res, child)
+			}
+		}
+		return nil
+	})
+	sort.Strings(res)
+	return res, err
+}
+
+func (c *cs) AllSettings() (map[string]any, error) {
+	res := map[string]any{}
+	err := c.Read("", &res)
+	if err != nil {
+		return nil, err
+	}
+	err = c.withCleanData(context.Background(), func() error {
+		existing := normalizedKeys(leafKeysOf("", c.root))
+		for _, k := range c.enumeratedKeys() {
+			if existing[normalizeKey(k)] {
+				continue
+			}
+			lbVal, _, err := c.lateBindingValue(newReadState(context.Background(), c.opts), k)
+			if err != nil {
+				return err
+			}
+			if lbVal != nil {
+				setNested(res, k, lbVal)
+			}
+		}
+		return nil
+	})
+	return res, err
+}
+
+// leafKeys returns the sorted full keys of all values which are not maps, including keys from enumerators which
+// aren't already present. Must be called with the lock held
+func (c *cs) leafKeys() []string {
+	res := leafKeysOf("", c.root)
+	existing := normalizedKeys(res)
+	for _, k := range c.enumeratedKeys() {
+		if n := normalizeKey(k); !existing[n] {
+			existing[n] = true
+			res = append(res, k)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// enumeratedKeys returns keys from all key enumerators. Must be called with the lock held
+func (c *cs) enumeratedKeys() []string {
+	var res []string
+	for _, e := range c.keyEnumerators {
+		res = append(res, e()...)
+	}
+	return res
+}
+
+func leafKeysOf(prefix string, data map[string]reflect.Value) []string {
+	var res []string
+	for k, v := range data {
+		fullKey := joinKey(prefix, k)
+		if m, ok := v.Interface().(map[string]reflect.Value); ok {
+			res = append(res, leafKeysOf(fullKey, m)...)
+		} else {
+			res = append(res, fullKey)
+		}
+	}
+	return res
+}
+
+func filterPrefix(keys []string, prefix string) []string {
+	if prefix == "" {
+		return keys
+	}
+	var res []string
+	for _, k := range keys {
+		if k == prefix || strings.HasPrefix(k, prefix+".") {
+			res = append(res, k)
+		}
+	}
+	return res
+}
+
+// normalizeKey reduces a key to a form where keys which map to the same late binding lookup are equal, for example
+// displayName and display.name
+func normalizeKey(key string) string {
+	return strings.NewReplacer(".", "", "_", "", "-", "").Replace(strings.ToLower(key))
+}
+
+func normalizedKeys(keys []string) map[string]bool {
+	res := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		res[normalizeKey(k)] = true
+	}
+	return res
+}
+
+// setNested sets a value in nested maps, creating intermediate maps as needed. Values which would replace a
+// non-map are skipped
+func setNested(data map[string]any, key string, val any) {
+	parts := strings.Split(key, ".")
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := data[part]
+		if !ok {
+			m := map[string]any{}
+			data[part] = m
+			data = m
+			continue
+		}
+		m, ok := next.(map[string]any)
+		if !ok {
+			return
+		}
+		data = m
+	}
+	last := parts[len(parts)-1]
+	if _, ok := data[last]; !ok {
+		data[last] = val
+	}
+}
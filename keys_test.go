@@ -0,0 +1,100 @@
+package cs_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/activatedio/cs"
+	"github.com/activatedio/cs/sources"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeys(t *testing.T) {
+
+	a := assert.New(t)
+
+	os.Setenv("TESTKEYS_PLUGINS_AUTH_ENABLED", "true")
+	os.Setenv("TESTKEYS_DISPLAY_NAME", "Display Name Override")
+
+	unit := cs.NewConfig()
+	unit.AddSource(func() (string, any, error) {
+		return "", map[string]any{
+			"displayName": "Display Name",
+			"feature": map[string]any{
+				"x": false,
+			},
+			"plugins": map[string]any{
+				"cache": map[string]any{
+					"size": 10,
+				},
+				"log": map[string]any{
+					"level":   "info",
+					"outputs": []any{"stdout"},
+				},
+			},
+		}, nil
+	})
+	unit.AddLateBindingSource(sources.NewEnvLateBindingSource("TESTKEYS"))
+	unit.AddKeyEnumerator(sources.NewEnvKeyEnumerator("TESTKEYS"))
+
+	for key, expected := range map[string]bool{
+		"":                     true,
+		"feature.x":            true,
+		"feature.y":            false,
+		"plugins":              true,
+		"plugins.auth":         true,
+		"plugins.auth.enabled": true,
+		"missing":              false,
+	} {
+		got, err := unit.Has(key)
+		a.NoError(err)
+		a.Equal(expected, got, key)
+	}
+
+	keys, err := unit.Keys("")
+	a.NoError(err)
+	a.Equal([]string{
+		"displayName",
+		"feature.x",
+		"plugins.auth.enabled",
+		"plugins.cache.size",
+		"plugins.log.level",
+		"plugins.log.outputs",
+	}, keys)
+
+	keys, err = unit.Keys("plugins.log")
+	a.NoError(err)
+	a.Equal([]string{
+		"plugins.log.level",
+		"plugins.log.outputs",
+	}, keys)
+
+	children, err := unit.Children("plugins")
+	a.NoError(err)
+	a.Equal([]string{"auth", "cache", "log"}, children)
+
+	children, err = unit.Children("")
+	a.NoError(err)
+	a.Equal([]string{"displayName", "feature", "plugins"}, children)
+
+	all, err := unit.AllSettings()
+	a.NoError(err)
+	a.Equal(map[string]any{
+		"displayName": "Display Name Override",
+		"feature": map[string]any{
+			"x": false,
+		},
+		"plugins": map[string]any{
+			"auth": map[string]any{
+				"enabled": "true",
+			},
+			"cache": map[string]any{
+				"size": 10,
+			},
+			"log": map[string]any{
+				"level":   "info",
+				"outputs": []any{"stdout"},
+			},
+		},
+	}, all)
+}
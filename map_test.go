@@ -0,0 +1,93 @@
+package cs_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/activatedio/cs"
+	"github.com/stretchr/testify/assert"
+)
+
+type testRegion string
+
+func (r *testRegion) UnmarshalText(text []byte) error {
+	if !strings.Contains(string(text), "-") {
+		return fmt.Errorf("invalid region %s", text)
+	}
+	*r = testRegion(strings.ToUpper(string(text)))
+	return nil
+}
+
+func TestTypedMaps(t *testing.T) {
+
+	type DBConfig struct {
+		Host string `cs:"host"`
+		Port int    `cs:"port,default=5432"`
+	}
+
+	type Config struct {
+		Labels    map[string]string          `cs:"labels"`
+		Limits    map[string]int             `cs:"limits"`
+		Databases map[string]DBConfig        `cs:"databases"`
+		Regions   map[testRegion]bool        `cs:"regions"`
+		Shards    map[int]string             `cs:"shards"`
+		Nested    map[string]map[string]bool `cs:"nested"`
+		Lists     map[string][]string        `cs:"lists"`
+	}
+
+	a := assert.New(t)
+
+	unit := cs.NewConfig()
+	unit.AddSource(func() (string, any, error) {
+		return "app", map[string]any{
+			"labels": map[string]any{"team": "core", "tier": 1},
+			"limits": map[string]any{"cpu": "2", "memory": 512},
+			"databases": map[string]any{
+				"main":    map[string]any{"host": "db1"},
+				"reports": map[string]any{"host": "db2", "port": 5433},
+			},
+			"regions": map[string]any{"us-east": true},
+			"shards":  map[string]any{"1": "a", "2": "b"},
+			"nested":  map[string]any{"x": map[string]any{"y": "true"}},
+			"lists":   map[string]any{"a": []any{"1", "2"}, "b": "3,4"},
+		}, nil
+	})
+	// Late binding sources override map entries
+	unit.AddLateBindingSource(func(key string) (any, error) {
+		switch key {
+		case "app.limits.cpu":
+			return "4", nil
+		case "app.databases.main.host":
+			return "primary", nil
+		}
+		return nil, nil
+	})
+
+	got := Config{}
+	a.NoError(unit.Read("app", &got))
+
+	a.Equal(map[string]string{"team": "core", "tier": "1"}, got.Labels)
+	a.Equal(map[string]int{"cpu": 4, "memory": 512}, got.Limits)
+	a.Equal(map[string]DBConfig{
+		"main":    {Host: "primary", Port: 5432},
+		"reports": {Host: "db2", Port: 5433},
+	}, got.Databases)
+	a.Equal(map[testRegion]bool{"US-EAST": true}, got.Regions)
+	a.Equal(map[int]string{1: "a", 2: "b"}, got.Shards)
+	a.Equal(map[string]map[string]bool{"x": {"y": true}}, got.Nested)
+	a.Equal(map[string][]string{"a": {"1", "2"}, "b": {"3", "4"}}, got.Lists)
+
+	var limits map[string]int64
+	a.NoError(unit.Read("app.limits", &limits))
+	a.Equal(map[string]int64{"cpu": 4, "memory": 512}, limits)
+
+	// Keys which can't be converted are reported
+	unit.AddSource(func() (string, any, error) {
+		return "app.shards.third", "c", nil
+	})
+	var shards map[int]string
+	var ce *cs.ConversionError
+	a.ErrorAs(unit.Read("app.shards", &shards), &ce)
+	a.Equal("app.shards.third", ce.Key)
+}
@@ -0,0 +1,83 @@
+package cs
+
+import "reflect"
+
+// Option configures how values are read. Options passed to NewConfig apply to every read of the config. Options passed
+// to a read apply to that read only, after those of the config
+type Option func(*readOptions)
+
+// readOptions holds the options of a read
+type readOptions struct {
+	// strictConversion fails conversions which lose information instead of falling back to the zero value
+	strictConversion bool
+	// strictKeys fails reads into structs when the config holds keys which match no field
+	strictKeys bool
+	// required holds full keys which must have a value
+	required map[string]bool
+	// timeLayouts replace the default layouts used to read times from strings
+	timeLayouts []string
+	// decodeHooks read values of the types they are keyed by
+	decodeHooks map[reflect.Type]DecodeHook
+	// found, when set, receives whether a read filled any value from a source or late binding source
+	found *bool
+}
+
+// WithStrictConversion enables or disables strict conversion. In strict mode values which can't be converted to their
+// destination, such as "abc" for an int, 300 for an int8 or 1.5 for an int, fail the read with a *ConversionError
+// instead of silently becoming the zero value or being truncated
+func WithStrictConversion(strict bool) Option {
+	return func(o *readOptions) {
+		o.strictConversion = strict
+	}
+}
+
+// WithStrictKeys enables or disables strict key checking. In strict mode a read into a struct fails with an
+// *UnknownKeyError for each key under the struct which matches no field, suggesting the closest field name when one is
+// similar, so misspelled keys such as `databse` are reported instead of being ignored
+func WithStrictKeys(strict bool) Option {
+	return func(o *readOptions) {
+		o.strictKeys = strict
+	}
+}
+
+// Required marks keys, given in full, which must have a value. Reads from a view created by Sub take keys relative to
+// the view. A read fails with an error wrapping ErrKeyNotFound for
+// each required key it populates which has no value from either a source or a late binding source. Values which are
+// present but empty, such as "", are not missing
+func Required(keys ...string) Option {
+	return func(o *readOptions) {
+		// The map is copied, as it may be shared with the options of the config
+		required := make(map[string]bool, len(o.required)+len(keys))
+		for k := range o.required {
+			required[k] = true
+		}
+		for _, k := range keys {
+			required[k] = true
+		}
+		o.required = required
+	}
+}
+
+// WithTimeLayouts sets the layouts, in the format of time.Parse, tried in order when reading a time.Time from a string.
+// By default RFC 3339 timestamps, with or without a zone, and dates are supported
+func WithTimeLayouts(layouts ...string) Option {
+	return func(o *readOptions) {
+		o.timeLayouts = layouts
+	}
+}
+
+// reportFound sets found to whether a read filled any value from a source or late binding source, rather than only
+// defaults and zero values
+func reportFound(found *bool) Option {
+	return func(o *readOptions) {
+		o.found = found
+	}
+}
+
+// withOptions returns a copy of the options with more options applied
+func (o readOptions) withOptions(opts ...Option) readOptions {
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
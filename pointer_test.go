@@ -0,0 +1,150 @@
+package cs_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/activatedio/cs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPointerDestinations(t *testing.T) {
+
+	type Inner struct {
+		Host string `cs:"host"`
+		Port int    `cs:"port" validate:"max=65535"`
+	}
+
+	type Config struct {
+		Name     *string           `cs:"name"`
+		Missing  *string           `cs:"missing"`
+		Default  *int              `cs:"default,default=3"`
+		Primary  *Inner            `cs:"primary"`
+		Replica  *Inner            `cs:"replica"`
+		Nested   **Inner           `cs:"nested"`
+		Extra    any               `cs:"extra"`
+		Tags     any               `cs:"tags"`
+		Unset    any               `cs:"unset"`
+		Backends map[string]*Inner `cs:"backends"`
+		Weights  []*int            `cs:"weights"`
+	}
+
+	a := assert.New(t)
+
+	unit := cs.NewConfig()
+	unit.AddSource(func() (string, any, error) {
+		return "app", map[string]any{
+			"name": "api",
+			"primary": map[string]any{
+				"host": "db1",
+			},
+			"nested": map[string]any{
+				"port": 5432,
+			},
+			"extra": map[string]any{
+				"a": 1,
+			},
+			"tags": []any{"x", "y"},
+			"backends": map[string]any{
+				"east": map[string]any{"host": "east"},
+			},
+			"weights": []any{1, 2},
+		}, nil
+	})
+	unit.AddLateBindingSource(func(key string) (any, error) {
+		if key == "app.primary.port" {
+			return "5433", nil
+		}
+		return nil, nil
+	})
+
+	got := Config{}
+	a.NoError(unit.Read("app", &got))
+
+	a.Equal("api", *got.Name)
+	a.Nil(got.Missing)
+	a.Equal(3, *got.Default)
+	a.Equal(&Inner{Host: "db1", Port: 5433}, got.Primary)
+	a.Nil(got.Replica)
+	a.Equal(5432, (*got.Nested).Port)
+	a.Equal(map[string]any{"a": 1}, got.Extra)
+	a.Equal([]any{"x", "y"}, got.Tags)
+	a.Nil(got.Unset)
+	a.Equal(map[string]*Inner{"east": {Host: "east"}}, got.Backends)
+	a.Len(got.Weights, 2)
+	a.Equal(2, *got.Weights[1])
+
+	// Existing pointers are copied rather than written through
+	shared := &Inner{Host: "shared"}
+	got = Config{Primary: shared}
+	a.NoError(unit.Read("app", &got))
+	a.Equal("db1", got.Primary.Host)
+	a.Equal("shared", shared.Host)
+
+	var name *string
+	a.NoError(unit.Read("app.name", &name))
+	a.Equal("api", *name)
+
+	var missing *string
+	a.NoError(unit.Read("app.missing", &missing))
+	a.Nil(missing)
+	a.ErrorIs(unit.Read("app.missing", &missing, cs.Required("app.missing")), cs.ErrKeyNotFound)
+
+	var extra any
+	a.NoError(unit.Read("app.primary", &extra))
+	a.Equal(map[string]any{"host": "db1"}, extra)
+
+	// Values behind pointers are validated
+	unit.AddSource(func() (string, any, error) {
+		return "app.replica.port", 70000, nil
+	})
+	var ve *cs.ValidationError
+	a.ErrorAs(unit.Read("app", &Config{}), &ve)
+}
+
+func TestPointerDestinations_LateBinding(t *testing.T) {
+
+	type DB struct {
+		Host string `cs:"host"`
+		Port int    `cs:"port,default=5432"`
+		User string `cs:"user,required"`
+	}
+
+	type Config struct {
+		DB      *DB  `cs:"db"`
+		Replica *DB  `cs:"replica"`
+		Nested  **DB `cs:"nested"`
+	}
+
+	a := assert.New(t)
+
+	// Values for struct pointers come only from late binding sources, without a key enumerator
+	unit := cs.NewConfig()
+	unit.AddLateBindingSource(func(key string) (any, error) {
+		switch key {
+		case "app.db.host", "app.nested.host":
+			return "envhost", nil
+		case "app.db.user", "app.nested.user":
+			return "envuser", nil
+		}
+		return nil, nil
+	})
+
+	got := Config{}
+	a.NoError(unit.Read("app", &got))
+
+	a.Equal(&DB{Host: "envhost", Port: 5432, User: "envuser"}, got.DB)
+	a.Equal("envhost", (*got.Nested).Host)
+	// Pointers without any configured values stay nil, even if their fields are required
+	a.Nil(got.Replica)
+
+	// Failing late binding sources are still reported
+	unit.AddLateBindingSource(func(key string) (any, error) {
+		if key == "app.replica.host" {
+			return nil, errors.New("unavailable")
+		}
+		return nil, nil
+	})
+	var se *cs.SourceError
+	a.ErrorAs(unit.Read("app", &Config{}), &se)
+}
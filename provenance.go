@@ -0,0 +1,148 @@
+package cs
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Origin describes where a value came from
+type Origin struct {
+	// Source is the name of the source
+	Source string
+	// Path is the file the value was read from, if any
+	Path string
+	// Line is the line within the file, if available
+	Line int
+	// EnvVar is the environment variable the value was read from, if any
+	EnvVar string
+}
+
+func (o Origin) String() string {
+	parts := []string{o.Source}
+	switch {
+	case o.Path != "" && o.Line > 0:
+		parts = append(parts, fmt.Sprintf("%s:%d", o.Path, o.Line))
+	case o.Path != "":
+		parts = append(parts, o.Path)
+	}
+	if o.EnvVar != "" {
+		parts = append(parts, fmt.Sprintf("$%s", o.EnvVar))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Annotated wraps a value returned from a Source or LateBindingSource with information about its origin
+type Annotated struct {
+	Value  any
+	Origin Origin
+	// Lines optionally maps dot separated keys, relative to the value, to the line they were defined on
+	Lines map[string]int
+}
+
+// Layers can be returned from a Source to supply several values under its key, such as one per file matched by a glob.
+// They are applied in order, so later values take precedence, and each may be Annotated with its own origin
+type Layers []any
+
+// Layer is the value a single source supplied for a key
+type Layer struct {
+	Value  any
+	Origin Origin
+}
+
+// Explanation describes how the value of a key was resolved
+type Explanation struct {
+	// Key is the full key
+	Key string
+	// Value is the winning value, or nil if no source supplied one
+	Value any
+	// Origin is the origin of the winning value
+	Origin Origin
+	// Chain lists every value supplied for the key, in order of increasing precedence. The last layer is the winner
+	Chain []Layer
+}
+
+// sourceName returns the name recorded in the origin of a value. Explicitly named providers use their own name,
+// while bare functions use the name from their annotation if present
+func sourceName(annotated, provider string, autoNamed bool) string {
+	if autoNamed && annotated != "" {
+		return annotated
+	}
+	return provider
+}
+
+// unwrapAnnotated returns the value and annotation of a value returned from a source
+func unwrapAnnotated(v any) (any, *Annotated) {
+	switch a := v.(type) {
+	case Annotated:
+		return a.Value, &a
+	case *Annotated:
+		if a == nil {
+			return nil, nil
+		}
+		return a.Value, a
+	default:
+		return v, nil
+	}
+}
+
+// recordOrigins appends a layer for each leaf of a loaded value to the provenance of its full key
+func recordOrigins(origins map[string][]Layer, key string, val reflect.Value, origin Origin, lines map[string]int) {
+	recordOriginsAt(origins, key, "", val, origin, lines)
+}
+
+func recordOriginsAt(origins map[string][]Layer, key, rel string, val reflect.Value, origin Origin, lines map[string]int) {
+	if m, ok := val.Interface().(map[string]reflect.Value); ok {
+		for k, v := range m {
+			recordOriginsAt(origins, joinKey(key, k), joinKey(rel, k), v, origin, lines)
+		}
+		return
+	}
+	o := origin
+	if line, ok := lines[rel]; ok {
+		o.Line = line
+	}
+	origins[key] = append(origins[key], Layer{
+		Value:  toInterface(val),
+		Origin: o,
+	})
+}
+
+func (c *cs) Explain(key string) (*Explanation, error) {
+	res := &Explanation{
+		Key: key,
+	}
+	err := c.withCleanData(context.Background(), func() error {
+		res.Chain = append(res.Chain, c.origins[key]...)
+		for _, entry := range c.lateBindingSources {
+			lbVal, err := lookupWithContext(context.Background(), entry.provider, key)
+			if err != nil {
+				return err
+			}
+			v, a := unwrapAnnotated(lbVal)
+			if v == nil {
+				continue
+			}
+			origin := Origin{}
+			if a != nil {
+				origin = a.Origin
+			}
+			origin.Source = sourceName(origin.Source, entry.provider.Name(), entry.autoNamed)
+			res.Chain = append(res.Chain, Layer{
+				Value:  v,
+				Origin: origin,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Chain) > 0 {
+		winner := res.Chain[len(res.Chain)-1]
+		res.Value = winner.Value
+		res.Origin = winner.Origin
+	}
+	return res, nil
+}
@@ -0,0 +1,333 @@
+package cs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrSourceNotFound is returned when removing or replacing a source which doesn't exist
+var ErrSourceNotFound = errors.New("source not found")
+
+// SourceProvider is a named source. Providers may also implement Watchable, in which case the config is reloaded
+// when they report a change, and io.Closer, in which case they are closed when removed or when the config is closed
+type SourceProvider interface {
+	// Name returns the name of the provider, which must be unique within a config
+	Name() string
+	// Load returns a key, the cs object, and an error
+	Load(ctx context.Context) (string, any, error)
+}
+
+// LateBindingProvider is a named late binding source. Providers may also implement Watchable, in which case the
+// config is reloaded and cached reads are discarded when they report a change, and io.Closer, in which case they are
+// closed when removed or when the config is closed
+type LateBindingProvider interface {
+	// Name returns the name of the provider, which must be unique within a config
+	Name() string
+	// Lookup returns a cs value for a given key at the time a csuration is read
+	Lookup(ctx context.Context, key string) (any, error)
+}
+
+type funcSourceProvider struct {
+	name string
+	src  Source
+}
+
+func (p *funcSourceProvider) Name() string {
+	return p.name
+}
+
+func (p *funcSourceProvider) Load(_ context.Context) (string, any, error) {
+	return p.src()
+}
+
+type watchableSourceProvider struct {
+	funcSourceProvider
+	watchable Watchable
+}
+
+func (p *watchableSourceProvider) Watch(notify func()) func() {
+	return p.watchable.Watch(notify)
+}
+
+type funcLateBindingProvider struct {
+	name string
+	src  LateBindingSource
+}
+
+func (p *funcLateBindingProvider) Name() string {
+	return p.name
+}
+
+func (p *funcLateBindingProvider) Lookup(_ context.Context, key string) (any, error) {
+	return p.src(key)
+}
+
+type watchableLateBindingProvider struct {
+	funcLateBindingProvider
+	watchable Watchable
+}
+
+func (p *watchableLateBindingProvider) Watch(notify func()) func() {
+	return p.watchable.Watch(notify)
+}
+
+// NewSourceProvider adapts a Source to a SourceProvider with the given name
+func NewSourceProvider(name string, src Source) SourceProvider {
+	return &funcSourceProvider{
+		name: name,
+		src:  src,
+	}
+}
+
+// NewWatchableSourceProvider adapts a Source and the Watchable reporting its changes to a SourceProvider with the
+// given name
+func NewWatchableSourceProvider(name string, src Source, w Watchable) SourceProvider {
+	return &watchableSourceProvider{
+		funcSourceProvider: funcSourceProvider{
+			name: name,
+			src:  src,
+		},
+		watchable: w,
+	}
+}
+
+// NewLateBindingProvider adapts a LateBindingSource to a LateBindingProvider with the given name
+func NewLateBindingProvider(name string, src LateBindingSource) LateBindingProvider {
+	return &funcLateBindingProvider{
+		name: name,
+		src:  src,
+	}
+}
+
+// NewWatchableLateBindingProvider adapts a LateBindingSource and the Watchable reporting changes to its data to a
+// LateBindingProvider with the given name
+func NewWatchableLateBindingProvider(name string, src LateBindingSource, w Watchable) LateBindingProvider {
+	return &watchableLateBindingProvider{
+		funcLateBindingProvider: funcLateBindingProvider{
+			name: name,
+			src:  src,
+		},
+		watchable: w,
+	}
+}
+
+// sourceEntry is a source provider registered with a cs
+type sourceEntry struct {
+	provider SourceProvider
+	// autoNamed is true for providers added as bare functions, whose names are only positional
+	autoNamed bool
+	// stop stops watching the provider, if it is watchable
+	stop func()
+}
+
+// lateBindingEntry is a late binding provider registered with a cs
+type lateBindingEntry struct {
+	provider  LateBindingProvider
+	autoNamed bool
+	// stop stops watching the provider, if it is watchable
+	stop func()
+}
+
+func (c *cs) AddSource(src Source) {
+	c.addSourceEntry(&sourceEntry{
+		provider:  NewSourceProvider(c.nextSourceName(), src),
+		autoNamed: true,
+	})
+}
+
+func (c *cs) AddWatchableSource(src Source, w Watchable) {
+	c.addSourceEntry(&sourceEntry{
+		provider:  NewWatchableSourceProvider(c.nextSourceName(), src, w),
+		autoNamed: true,
+	})
+}
+
+// nextSourceName returns a positional name for a source added as a bare function
+func (c *cs) nextSourceName() string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	name := fmt.Sprintf("source[%d]", c.nextSourceID)
+	c.nextSourceID++
+	return name
+}
+
+func (c *cs) AddSourceProvider(p SourceProvider) {
+	c.addSourceEntry(&sourceEntry{
+		provider: p,
+	})
+}
+
+func (c *cs) addSourceEntry(entry *sourceEntry) {
+
+	// Watching is started outside the lock, as watchables may notify immediately
+	if w, ok := entry.provider.(Watchable); ok {
+		entry.stop = w.Watch(c.onChange)
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for i, existing := range c.sources {
+		if existing.provider.Name() == entry.provider.Name() {
+			// Adding a provider with an existing name replaces it in place
+			c.sources[i] = entry
+			c.dirty = true
+			c.gen++
+			existing.release()
+			return
+		}
+	}
+
+	c.sources = append(c.sources, entry)
+	c.dirty = true
+	c.gen++
+}
+
+func (c *cs) ReplaceSource(name string, p SourceProvider) error {
+
+	entry := &sourceEntry{
+		provider: p,
+	}
+	if w, ok := p.(Watchable); ok {
+		entry.stop = w.Watch(c.onChange)
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for i, existing := range c.sources {
+		if existing.provider.Name() == name {
+			c.sources[i] = entry
+			c.dirty = true
+			c.gen++
+			existing.release()
+			return nil
+		}
+	}
+
+	entry.release()
+
+	return fmt.Errorf("%w: %s", ErrSourceNotFound, name)
+}
+
+func (c *cs) RemoveSource(name string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for i, existing := range c.sources {
+		if existing.provider.Name() == name {
+			c.sources = append(c.sources[:i:i], c.sources[i+1:]...)
+			c.dirty = true
+			c.gen++
+			existing.release()
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s", ErrSourceNotFound, name)
+}
+
+func (c *cs) AddLateBindingSource(src LateBindingSource) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.addLateBindingEntry(&lateBindingEntry{
+		provider:  NewLateBindingProvider(fmt.Sprintf("late binding source[%d]", c.nextLateBindingID), src),
+		autoNamed: true,
+	})
+	c.nextLateBindingID++
+}
+
+func (c *cs) AddLateBindingProvider(p LateBindingProvider) {
+
+	entry := &lateBindingEntry{
+		provider: p,
+	}
+
+	// Watching is started outside the lock, as watchables may notify immediately
+	if w, ok := p.(Watchable); ok {
+		entry.stop = w.Watch(c.onChange)
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.addLateBindingEntry(entry)
+}
+
+// addLateBindingEntry adds or replaces a late binding provider. Must be called with the lock held
+func (c *cs) addLateBindingEntry(entry *lateBindingEntry) {
+	c.dirty = true
+	c.gen++
+
+	for i, existing := range c.lateBindingSources {
+		if existing.provider.Name() == entry.provider.Name() {
+			c.lateBindingSources[i] = entry
+			existing.release()
+			return
+		}
+	}
+
+	c.lateBindingSources = append(c.lateBindingSources, entry)
+}
+
+func (c *cs) RemoveLateBindingSource(name string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for i, existing := range c.lateBindingSources {
+		if existing.provider.Name() == name {
+			c.lateBindingSources = append(c.lateBindingSources[:i:i], c.lateBindingSources[i+1:]...)
+			c.dirty = true
+			c.gen++
+			existing.release()
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s", ErrSourceNotFound, name)
+}
+
+func (c *cs) Close() {
+	c.lock.Lock()
+	if c.closed {
+		c.lock.Unlock()
+		return
+	}
+	c.closed = true
+	sources := c.sources
+	lateBindingSources := c.lateBindingSources
+	c.lock.Unlock()
+
+	for _, entry := range sources {
+		entry.release()
+	}
+	for _, entry := range lateBindingSources {
+		entry.release()
+	}
+}
+
+// release stops watching the provider and closes it
+func (e *sourceEntry) release() {
+	if e.stop != nil {
+		e.stop()
+	}
+	closeProvider(e.provider)
+}
+
+// release stops watching the provider and closes it
+func (e *lateBindingEntry) release() {
+	if e.stop != nil {
+		e.stop()
+	}
+	closeProvider(e.provider)
+}
+
+func closeProvider(p any) {
+	if c, ok := p.(io.Closer); ok {
+		_ = c.Close()
+	}
+}
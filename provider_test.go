@@ -0,0 +1,89 @@
+package cs_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/activatedio/cs"
+	"github.com/stretchr/testify/assert"
+)
+
+type closingProvider struct {
+	name   string
+	value  any
+	closed bool
+}
+
+func (p *closingProvider) Name() string {
+	return p.name
+}
+
+func (p *closingProvider) Load(_ context.Context) (string, any, error) {
+	return "key1", p.value, nil
+}
+
+func (p *closingProvider) Close() error {
+	p.closed = true
+	return nil
+}
+
+func TestSourceProviders(t *testing.T) {
+
+	a := assert.New(t)
+
+	unit := cs.NewConfig()
+
+	base := &closingProvider{name: "base", value: "a"}
+	unit.AddSourceProvider(base)
+	unit.AddSource(func() (string, any, error) {
+		return "key2", "b", nil
+	})
+	unit.AddSourceProvider(cs.NewSourceProvider("override", func() (string, any, error) {
+		return "key1", "c", nil
+	}))
+	unit.AddLateBindingProvider(cs.NewLateBindingProvider("lookup", func(key string) (any, error) {
+		if key == "key3" {
+			return "d", nil
+		}
+		return nil, nil
+	}))
+
+	read := func(key string) string {
+		var got string
+		unit.MustRead(key, &got)
+		return got
+	}
+
+	a.Equal("c", read("key1"))
+	a.Equal("b", read("key2"))
+	a.Equal("d", read("key3"))
+
+	ex, err := unit.Explain("key1")
+	a.NoError(err)
+	a.Equal([]cs.Layer{
+		{Value: "a", Origin: cs.Origin{Source: "base"}},
+		{Value: "c", Origin: cs.Origin{Source: "override"}},
+	}, ex.Chain)
+
+	// Replacing keeps precedence, so the replacement is still overridden
+	replacement := &closingProvider{name: "replacement", value: "e"}
+	a.NoError(unit.ReplaceSource("base", replacement))
+	a.True(base.closed)
+	a.Equal("c", read("key1"))
+
+	a.NoError(unit.RemoveSource("override"))
+	a.Equal("e", read("key1"))
+
+	a.NoError(unit.RemoveSource("source[0]"))
+	a.Equal("", read("key2"))
+
+	a.NoError(unit.RemoveLateBindingSource("lookup"))
+	a.Equal("", read("key3"))
+
+	a.ErrorIs(unit.RemoveSource("override"), cs.ErrSourceNotFound)
+	a.ErrorIs(unit.ReplaceSource("missing", &closingProvider{name: "missing"}), cs.ErrSourceNotFound)
+	a.ErrorIs(unit.RemoveLateBindingSource("lookup"), cs.ErrSourceNotFound)
+
+	unit.Close()
+	a.True(replacement.closed)
+}
@@ -0,0 +1,74 @@
+package cs_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/activatedio/cs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequired(t *testing.T) {
+
+	type Database struct {
+		Host     string   `cs:"host,required"`
+		Port     int      `cs:"port"`
+		User     string   `cs:"user"`
+		Replicas []string `cs:"replicas"`
+	}
+
+	a := assert.New(t)
+
+	unit := cs.NewConfig()
+	unit.AddSource(func() (string, any, error) {
+		return "db", map[string]any{
+			"user": "",
+		}, nil
+	})
+
+	// Missing keys are left as zero values unless required
+	var port int
+	a.NoError(unit.Read("db.port", &port))
+
+	err := unit.Read("db.port", &port, cs.Required("db.port"))
+	a.ErrorIs(err, cs.ErrKeyNotFound)
+	var ke *cs.KeyError
+	a.ErrorAs(err, &ke)
+	a.Equal("db.port", ke.Key)
+	a.EqualError(err, "key db.port: key not found")
+
+	// Empty values are present
+	var user string
+	a.NoError(unit.Read("db.user", &user, cs.Required("db.user")))
+
+	err = unit.Read("db", &Database{}, cs.Required("db.port", "db.replicas", "db.user"))
+	var ae *cs.AggregateError
+	a.ErrorAs(err, &ae)
+	a.Len(ae.Errors, 3)
+	for i, k := range []string{"db.host", "db.port", "db.replicas"} {
+		a.ErrorAs(ae.Errors[i], &ke)
+		a.Equal(k, ke.Key)
+		a.True(errors.Is(ae.Errors[i], cs.ErrKeyNotFound))
+	}
+
+	// Late binding sources supply values for required keys
+	unit.AddLateBindingSource(func(key string) (any, error) {
+		switch key {
+		case "db.host":
+			return "localhost", nil
+		case "db.port":
+			return "5432", nil
+		case "db.replicas":
+			return "a,b", nil
+		}
+		return nil, nil
+	})
+
+	got := Database{}
+	a.NoError(unit.Read("db", &got, cs.Required("db.port", "db.replicas")))
+	a.Equal(Database{Host: "localhost", Port: 5432, Replicas: []string{"a", "b"}}, got)
+
+	// Required keys given to the config apply to every read
+	unit = cs.NewConfig(cs.Required("db.port"))
+	a.ErrorIs(unit.Read("db.port", &port), cs.ErrKeyNotFound)
+}
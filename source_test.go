@@ -0,0 +1,89 @@
+package cs_test
+
+import (
+	"testing"
+
+	"github.com/activatedio/cs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSourceNormalization(t *testing.T) {
+
+	type Inner struct {
+		Host string `cs:"host"`
+	}
+
+	type Source struct {
+		Primary *Inner `cs:"primary"`
+		Replica *Inner `cs:"replica"`
+		Extra   any    `cs:"extra"`
+	}
+
+	type Port int
+
+	a := assert.New(t)
+
+	host := "db1"
+	hostPtr := &host
+
+	unit := cs.NewConfig()
+	unit.AddSource(func() (string, any, error) {
+		return "app", map[string]any{
+			"labels": map[string]string{"team": "core"},
+			"limits": map[string]int{"cpu": 2},
+			"legacy": map[any]any{
+				"name":  "old",
+				1:       "one",
+				true:    "yes",
+				"inner": map[any]any{"port": Port(80)},
+			},
+			"typed":  map[Port]map[string]*string{8080: {"host": hostPtr}},
+			"ptr":    &hostPtr,
+			"nil":    nil,
+			"nilPtr": (*string)(nil),
+			"list":   []any{"a", nil, "c"},
+			"source": &Source{Primary: &Inner{Host: "p"}},
+		}, nil
+	})
+	unit.AddSource(func() (string, any, error) {
+		// A source returning nil contributes nothing
+		return "app.labels", nil, nil
+	})
+
+	got := map[string]any{}
+	a.NoError(unit.Read("app", &got))
+
+	a.Equal(map[string]any{
+		"labels": map[string]any{"team": "core"},
+		"limits": map[string]any{"cpu": 2},
+		"legacy": map[string]any{
+			"name":  "old",
+			"1":     "one",
+			"true":  "yes",
+			"inner": map[string]any{"port": Port(80)},
+		},
+		"typed":  map[string]any{"8080": map[string]any{"host": "db1"}},
+		"ptr":    "db1",
+		"list":   []any{"a", nil, "c"},
+		"source": map[string]any{"primary": map[string]any{"host": "p"}},
+	}, got)
+
+	var port int
+	a.NoError(unit.Read("app.legacy.inner.port", &port))
+	a.Equal(80, port)
+
+	has, err := unit.Has("app.nil")
+	a.NoError(err)
+	a.False(has)
+
+	unit = cs.NewConfig()
+	unit.AddSource(func() (string, any, error) {
+		return "app", map[[2]int]string{{1, 2}: "x"}, nil
+	})
+	var se *cs.SourceError
+	var ke *cs.KeyError
+	err = unit.Read("app", &got)
+	a.ErrorAs(err, &se)
+	a.ErrorAs(err, &ke)
+	a.EqualError(err, "source source[0]: key app: unsupported map key type [2]int")
+}
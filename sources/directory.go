@@ -0,0 +1,241 @@
+package sources
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/activatedio/cs"
+)
+
+// dataLink is the symlink Kubernetes swaps atomically to update the files of a mounted ConfigMap or Secret
+const dataLink = "..data"
+
+// Decoder parses the contents of a file into a value which can be returned from a cs.Source
+type Decoder func(data []byte) (any, error)
+
+// DirectoryOption configures a source created by NewDirectorySource
+type DirectoryOption func(*directoryOptions)
+
+type directoryOptions struct {
+	decoders map[string]Decoder
+}
+
+// WithDecoder parses files with the given extension, such as ".yaml", using a decoder, rather than reading them as
+// strings. The extension is removed from the key, so config.yaml is stored under config
+func WithDecoder(ext string, d Decoder) DirectoryOption {
+	return func(o *directoryOptions) {
+		o.decoders[strings.ToLower(ext)] = d
+	}
+}
+
+// NewDirectorySource creates a source which reads a directory with one file per key, such as a Kubernetes ConfigMap or
+// Secret mount
+//
+// A non-empty keyPrefix will prepend the prefix to stored keys, in format [keyPrefix].[key]
+//
+// File names are split on dots into keys, so a file named db.host is stored as db.host, and nested directories become
+// nested keys. Files are read as strings with trailing newlines removed, unless a decoder is registered for their
+// extension with WithDecoder. Names starting with .. are skipped, and if the directory has a ..data symlink, files are
+// read through it so that a single version of the mount is seen while Kubernetes swaps it
+func NewDirectorySource(dir, keyPrefix string, opts ...DirectoryOption) cs.Source {
+
+	o := directoryOptions{
+		decoders: map[string]Decoder{},
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return func() (string, any, error) {
+
+		res := map[string]any{}
+
+		err := walkDirectory(dataDir(dir), nil, func(names []string, path string, _ os.FileInfo) error {
+
+			data, err := os.ReadFile(path) //nolint:gosec // users of this library should never use user input for this value
+
+			if err != nil {
+				return err
+			}
+
+			name := names[len(names)-1]
+
+			var val any
+
+			if d, ok := o.decoders[strings.ToLower(filepath.Ext(name))]; ok {
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+				if val, err = d(data); err != nil {
+					return fmt.Errorf("%s: %w", path, err)
+				}
+				if val == nil {
+					return nil
+				}
+			} else {
+				val = strings.TrimRight(string(data), "\r\n")
+			}
+
+			var key []string
+			for _, n := range append(names[:len(names)-1:len(names)-1], name) {
+				for _, part := range strings.Split(n, ".") {
+					if part != "" {
+						key = append(key, part)
+					}
+				}
+			}
+
+			if len(key) == 0 {
+				return nil
+			}
+
+			if err = setDirectoryKey(res, key, val); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+
+			return nil
+		})
+
+		if err != nil {
+			return "", nil, err
+		}
+
+		return keyPrefix, cs.Annotated{
+			Value: res,
+			Origin: cs.Origin{
+				Source: "directory",
+				Path:   dir,
+			},
+		}, nil
+	}
+}
+
+// NewWatchableDirectorySource creates a source in the same way as NewDirectorySource, along with a cs.Watchable from
+// NewDirectoryWatcher. The results can be passed directly to cs.Config.AddWatchableSource
+func NewWatchableDirectorySource(dir, keyPrefix string, opts ...DirectoryOption) (cs.Source, cs.Watchable) {
+	return NewDirectorySource(dir, keyPrefix, opts...), NewDirectoryWatcher(DefaultPollInterval, dir)
+}
+
+// NewDirectoryWatcher creates a cs.Watchable which polls a directory at the provided interval, reporting a change when
+// a file beneath it is added, removed or changed, or when its ..data symlink is swapped to a new version
+func NewDirectoryWatcher(interval time.Duration, dir string) cs.Watchable {
+	return &fileWatcher{
+		interval: interval,
+		snapshot: func() []fileState {
+
+			var res []fileState
+
+			if target, err := os.Readlink(filepath.Join(dir, dataLink)); err == nil {
+				res = append(res, fileState{path: dataLink, target: target, exists: true})
+			}
+
+			// Errors leave the snapshot incomplete, which is reported as a change once the directory can be read again
+			_ = walkDirectory(dataDir(dir), nil, func(names []string, _ string, fi os.FileInfo) error {
+				res = append(res, fileState{
+					path:    filepath.Join(names...),
+					exists:  true,
+					modTime: fi.ModTime(),
+					size:    fi.Size(),
+				})
+				return nil
+			})
+
+			return res
+		},
+	}
+}
+
+// dataDir returns the directory files should be read from, resolving the ..data symlink if present
+func dataDir(dir string) string {
+	if resolved, err := filepath.EvalSymlinks(filepath.Join(dir, dataLink)); err == nil {
+		return resolved
+	}
+	return dir
+}
+
+// walkDirectory calls fn for each file beneath dir, following symlinks, with the names of the directories leading to
+// the file and the file itself. Names starting with .. are skipped
+func walkDirectory(dir string, names []string, fn func(names []string, path string, fi os.FileInfo) error) error {
+
+	entries, err := os.ReadDir(dir)
+
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+
+		if strings.HasPrefix(e.Name(), "..") {
+			continue
+		}
+
+		path := filepath.Join(dir, e.Name())
+
+		fi, err := os.Stat(path)
+
+		if err != nil {
+			return err
+		}
+
+		_names := append(names[:len(names):len(names)], e.Name())
+
+		if fi.IsDir() {
+			err = walkDirectory(path, _names, fn)
+		} else {
+			err = fn(_names, path, fi)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setDirectoryKey stores a value at the given path of nested maps, merging maps decoded from files with keys from
+// other files
+func setDirectoryKey(m map[string]any, key []string, val any) error {
+
+	for i, k := range key[:len(key)-1] {
+		switch v := m[k].(type) {
+		case nil:
+			next := map[string]any{}
+			m[k] = next
+			m = next
+		case map[string]any:
+			m = v
+		default:
+			return fmt.Errorf("key %s conflicts with the value of %s", strings.Join(key, "."),
+				strings.Join(key[:i+1], "."))
+		}
+	}
+
+	return mergeDirectoryValue(m, key[len(key)-1], strings.Join(key, "."), val)
+}
+
+// mergeDirectoryValue stores a value under name in m, merging it into an existing map if both are maps
+func mergeDirectoryValue(m map[string]any, name, key string, val any) error {
+
+	existing, ok := m[name]
+	if !ok {
+		m[name] = val
+		return nil
+	}
+
+	em, eok := existing.(map[string]any)
+	vm, vok := val.(map[string]any)
+	if !eok || !vok {
+		return fmt.Errorf("key %s is defined more than once", key)
+	}
+
+	for k, v := range vm {
+		if err := mergeDirectoryValue(em, k, key+"."+k, v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
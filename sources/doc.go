@@ -1,4 +1,5 @@
 // Package sources contains various cs sources
 //
-// json and yaml sources are in separate packages to minimize imported libraries
+// json and yaml sources are in separate packages to minimize imported libraries. The toml source is a separate module,
+// so the core module doesn't depend on a toml library
 package sources
@@ -0,0 +1,245 @@
+// Package dotenv supports cs sources from .env files
+//
+// Variables are named in the same way as environment variables read by sources.NewEnvLateBindingSource, so a .env file
+// can provide values for local development which are otherwise set in the environment
+//
+// NewSourceFromPath stores each variable under the key its name maps back to, so DB_HOST is stored as db.host. As case
+// can't be recovered from names, keys such as db.maxConns are best served by a late binding source, which resolves
+// them to DB_MAX_CONNS in the same way as environment variables, along with a key enumerator listing the keys present:
+//
+//	cfg.AddLateBindingSource(dotenv.NewLateBindingSourceFromPath(".env", "APP"))
+//	cfg.AddKeyEnumerator(dotenv.NewKeyEnumeratorFromPath(".env", "APP"))
+package dotenv
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/activatedio/cs"
+	"github.com/activatedio/cs/sources"
+)
+
+// file caches the variables parsed from a .env file, parsing it again when its modification time or size changes
+type file struct {
+	path    string
+	lock    sync.Mutex
+	parsed  bool
+	modTime time.Time
+	size    int64
+	// vars holds the last assignment of each variable
+	vars map[string]variable
+}
+
+func newFile(path string) *file {
+	return &file{
+		path: path,
+	}
+}
+
+// variables returns the variables assigned in the file, keyed by name
+func (f *file) variables() (map[string]variable, error) {
+
+	fi, err := os.Stat(f.path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.parsed && fi.ModTime().Equal(f.modTime) && fi.Size() == f.size {
+		return f.vars, nil
+	}
+
+	data, err := os.ReadFile(f.path) //nolint:gosec // users of this library should never use user input for this value
+
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := parse(string(data))
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", f.path, err)
+	}
+
+	vars := make(map[string]variable, len(parsed))
+	for _, v := range parsed {
+		vars[v.name] = v
+	}
+
+	f.parsed = true
+	f.modTime = fi.ModTime()
+	f.size = fi.Size()
+	f.vars = vars
+
+	return vars, nil
+}
+
+// NewSourceFromPath creates a new source by parsing a .env file at the given path
+//
+// Upper snake case names are converted into dot-separated lower case keys, in the same way as
+// sources.NewEnvKeyEnumerator, so [envPrefix]_DB_HOST is stored as db.host. If envPrefix is non-empty, variables without
+// the prefix are ignored. Variables with empty values are ignored, as they are for environment variables. Names which
+// would nest under the key of another variable, such as NAME and NAME_SUFFIX, fail the load, and files using them
+// should be read with NewLateBindingSourceFromPath instead
+func NewSourceFromPath(path, envPrefix string) cs.Source {
+
+	f := newFile(path)
+
+	return func() (string, any, error) {
+
+		vars, err := f.variables()
+
+		if err != nil {
+			return "", nil, err
+		}
+
+		// Variables are stored in the order they appear, so conflicts are reported against the later line
+		ordered := make([]variable, 0, len(vars))
+		for _, v := range vars {
+			ordered = append(ordered, v)
+		}
+		sort.Slice(ordered, func(i, j int) bool {
+			return ordered[i].line < ordered[j].line
+		})
+
+		res := map[string]any{}
+		lines := map[string]int{}
+
+		for _, v := range ordered {
+
+			key, ok := sources.EnvVarKey(v.name, envPrefix)
+			if !ok || v.value == "" {
+				continue
+			}
+
+			if err = setKey(res, strings.Split(key, "."), v.value); err != nil {
+				return "", nil, fmt.Errorf("%s: line %d: %s %w", path, v.line, v.name, err)
+			}
+
+			lines[key] = v.line
+		}
+
+		return "", cs.Annotated{
+			Value: res,
+			Origin: cs.Origin{
+				Source: "dotenv",
+				Path:   path,
+			},
+			Lines: lines,
+		}, nil
+	}
+}
+
+// NewWatchableSourceFromPath creates a source in the same way as NewSourceFromPath, along with a cs.Watchable which
+// polls the file for changes. The results can be passed directly to cs.Config.AddWatchableSource
+func NewWatchableSourceFromPath(path, envPrefix string) (cs.Source, cs.Watchable) {
+	return NewSourceFromPath(path, envPrefix), sources.NewFileWatcher(sources.DefaultPollInterval, path)
+}
+
+// setKey stores a value at the given path of nested maps
+func setKey(m map[string]any, path []string, val string) error {
+
+	for i, p := range path[:len(path)-1] {
+		switch v := m[p].(type) {
+		case nil:
+			next := map[string]any{}
+			m[p] = next
+			m = next
+		case map[string]any:
+			m = v
+		default:
+			return fmt.Errorf("conflicts with the value of %s", strings.Join(path[:i+1], "."))
+		}
+	}
+
+	last := path[len(path)-1]
+	if _, ok := m[last].(map[string]any); ok {
+		return fmt.Errorf("conflicts with keys under %s", strings.Join(path, "."))
+	}
+
+	m[last] = val
+	return nil
+}
+
+// NewLateBindingSourceFromPath creates a cs.LateBindingSource which reads variables from a .env file at the given path
+//
+// Keys are converted to names in the same way as sources.NewEnvLateBindingSource, so db.maxConns is read from
+// [envPrefix]_DB_MAX_CONNS. Variables with empty values are ignored, as they are for environment variables. The file is
+// parsed once, and parsed again when it changes. Reads are cached by the config, however, so changes are only seen
+// once it reloads. Use NewWatchableLateBindingSourceFromPath to reload the config when the file changes
+func NewLateBindingSourceFromPath(path, envPrefix string) cs.LateBindingSource {
+
+	f := newFile(path)
+
+	return func(key string) (any, error) {
+
+		vars, err := f.variables()
+
+		if err != nil {
+			return nil, err
+		}
+
+		name := sources.EnvVarName(key, envPrefix)
+
+		v, ok := vars[name]
+		if !ok || v.value == "" {
+			return nil, nil
+		}
+
+		return cs.Annotated{
+			Value: v.value,
+			Origin: cs.Origin{
+				Source: "dotenv",
+				Path:   path,
+				Line:   v.line,
+				EnvVar: name,
+			},
+		}, nil
+	}
+}
+
+// NewWatchableLateBindingSourceFromPath creates a late binding source in the same way as NewLateBindingSourceFromPath,
+// along with a cs.Watchable which polls the file for changes. The results can be passed to
+// cs.NewWatchableLateBindingProvider and added with cs.Config.AddLateBindingProvider
+func NewWatchableLateBindingSourceFromPath(path, envPrefix string) (cs.LateBindingSource, cs.Watchable) {
+	return NewLateBindingSourceFromPath(path, envPrefix), sources.NewFileWatcher(sources.DefaultPollInterval, path)
+}
+
+// NewKeyEnumeratorFromPath creates a cs.KeyEnumerator which lists keys for the variables in a .env file, for use
+// alongside NewLateBindingSourceFromPath with the same path and envPrefix
+//
+// Names are converted into keys in the same way as sources.NewEnvKeyEnumerator. If the file can't be read, no keys are
+// listed, and the error is reported by the late binding source
+func NewKeyEnumeratorFromPath(path, envPrefix string) cs.KeyEnumerator {
+
+	f := newFile(path)
+
+	return func() []string {
+
+		vars, err := f.variables()
+
+		if err != nil {
+			return nil
+		}
+
+		var res []string
+		for name, v := range vars {
+			if v.value == "" {
+				continue
+			}
+			if key, ok := sources.EnvVarKey(name, envPrefix); ok {
+				res = append(res, key)
+			}
+		}
+
+		sort.Strings(res)
+		return res
+	}
+}
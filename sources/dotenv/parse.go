@@ -0,0 +1,314 @@
+package dotenv
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// variable is a single assignment in a .env file
+type variable struct {
+	name  string
+	value string
+	line  int
+}
+
+// parser reads assignments from the contents of a .env file
+type parser struct {
+	src  string
+	pos  int
+	line int
+	// vars holds the variables assigned so far, for expansion
+	vars map[string]string
+}
+
+// parse reads the variables assigned in the contents of a .env file, in the order they are assigned
+//
+// Each line has the format [export] NAME=value. Values may be unquoted, single quoted or double quoted. Single quoted
+// values are literal, while double quoted values support escapes and may span multiple lines. Unquoted and double
+// quoted values expand ${NAME} and $NAME from variables assigned earlier in the file, falling back to the process
+// environment. Lines starting with # and text following # after whitespace in unquoted values are comments
+func parse(src string) ([]variable, error) {
+
+	p := &parser{
+		src:  strings.ReplaceAll(src, "\r\n", "\n"),
+		line: 1,
+		vars: map[string]string{},
+	}
+
+	var res []variable
+
+	for {
+		p.skipBlank()
+		if p.eof() {
+			return res, nil
+		}
+
+		v, err := p.assignment()
+		if err != nil {
+			return nil, err
+		}
+
+		p.vars[v.name] = v.value
+		res = append(res, v)
+	}
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *parser) peek() byte {
+	return p.src[p.pos]
+}
+
+// next consumes a single byte, tracking lines
+func (p *parser) next() byte {
+	c := p.src[p.pos]
+	p.pos++
+	if c == '\n' {
+		p.line++
+	}
+	return c
+}
+
+// skipSpace consumes spaces and tabs
+func (p *parser) skipSpace() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.next()
+	}
+}
+
+// skipLine consumes the remainder of the current line
+func (p *parser) skipLine() {
+	for !p.eof() && p.next() != '\n' {
+	}
+}
+
+// skipBlank consumes whitespace, empty lines and comment lines
+func (p *parser) skipBlank() {
+	for !p.eof() {
+		switch p.peek() {
+		case ' ', '\t', '\n':
+			p.next()
+		case '#':
+			p.skipLine()
+		default:
+			return
+		}
+	}
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+// assignment reads a single [export] NAME=value line
+func (p *parser) assignment() (variable, error) {
+
+	line := p.line
+
+	name := p.name()
+	if name == "export" && !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.skipSpace()
+		name = p.name()
+	}
+
+	if name == "" {
+		return variable{}, p.errorf("expected variable name")
+	}
+
+	p.skipSpace()
+	if p.eof() || p.peek() != '=' {
+		return variable{}, p.errorf("expected = after %s", name)
+	}
+	p.next()
+	p.skipSpace()
+
+	var value string
+	var err error
+
+	switch {
+	case p.eof():
+	case p.peek() == '\'':
+		value, err = p.singleQuoted()
+	case p.peek() == '"':
+		value, err = p.doubleQuoted()
+	default:
+		value = p.unquoted()
+	}
+
+	if err != nil {
+		return variable{}, err
+	}
+
+	return variable{name: name, value: value, line: line}, nil
+}
+
+// name reads a variable name made up of letters, digits and underscores
+func (p *parser) name() string {
+	start := p.pos
+	for !p.eof() && isNameByte(p.peek()) {
+		p.next()
+	}
+	return p.src[start:p.pos]
+}
+
+func isNameByte(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func isNotNameRune(r rune) bool {
+	return r > 0x7f || !isNameByte(byte(r))
+}
+
+// unquoted reads the remainder of the line, stopping at a comment, and expands variables
+func (p *parser) unquoted() string {
+
+	start := p.pos
+	end := p.pos
+
+	for !p.eof() && p.peek() != '\n' {
+		if p.peek() == '#' && p.pos > start && (p.src[p.pos-1] == ' ' || p.src[p.pos-1] == '\t') {
+			p.skipLine()
+			break
+		}
+		p.next()
+		end = p.pos
+	}
+
+	return p.expand(strings.TrimRight(p.src[start:end], " \t"))
+}
+
+// singleQuoted reads a literal value up to the closing quote
+func (p *parser) singleQuoted() (string, error) {
+
+	line := p.line
+	p.next()
+
+	start := p.pos
+	for !p.eof() && p.peek() != '\'' {
+		p.next()
+	}
+
+	if p.eof() {
+		return "", fmt.Errorf("line %d: unterminated single quoted value", line)
+	}
+
+	value := p.src[start:p.pos]
+	p.next()
+
+	return value, p.endOfValue()
+}
+
+// doubleQuoted reads a value up to the closing quote, handling escapes and expanding variables
+func (p *parser) doubleQuoted() (string, error) {
+
+	line := p.line
+	p.next()
+
+	var sb strings.Builder
+
+	for {
+		if p.eof() {
+			return "", fmt.Errorf("line %d: unterminated double quoted value", line)
+		}
+
+		c := p.next()
+
+		switch c {
+		case '"':
+			return sb.String(), p.endOfValue()
+		case '\\':
+			if p.eof() {
+				continue
+			}
+			switch e := p.next(); e {
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			case '"', '\\', '$':
+				sb.WriteByte(e)
+			default:
+				sb.WriteByte('\\')
+				sb.WriteByte(e)
+			}
+		case '$':
+			p.pos--
+			sb.WriteString(p.variableRef())
+		default:
+			sb.WriteByte(c)
+		}
+	}
+}
+
+// endOfValue checks only whitespace or a comment follows a quoted value on its line
+func (p *parser) endOfValue() error {
+	p.skipSpace()
+	switch {
+	case p.eof(), p.peek() == '\n':
+	case p.peek() == '#':
+		p.skipLine()
+	default:
+		return p.errorf("unexpected %q after quoted value", p.peek())
+	}
+	return nil
+}
+
+// expand replaces variable references in s
+func (p *parser) expand(s string) string {
+
+	if !strings.Contains(s, "$") {
+		return s
+	}
+
+	sub := &parser{src: s, vars: p.vars}
+
+	var sb strings.Builder
+	for !sub.eof() {
+		if sub.peek() == '$' {
+			sb.WriteString(sub.variableRef())
+		} else {
+			sb.WriteByte(sub.next())
+		}
+	}
+
+	return sb.String()
+}
+
+// variableRef reads a ${NAME} or $NAME reference and returns its value. A $ which doesn't start a reference is kept
+func (p *parser) variableRef() string {
+
+	start := p.pos
+	p.next()
+
+	if !p.eof() && p.peek() == '{' {
+		end := strings.IndexByte(p.src[p.pos:], '}')
+		if end < 0 || strings.IndexFunc(p.src[p.pos+1:p.pos+end], isNotNameRune) >= 0 {
+			return p.src[start:p.pos]
+		}
+		closing := p.pos + end
+		name := p.src[p.pos+1 : closing]
+		for p.pos <= closing {
+			p.next()
+		}
+		return p.lookup(name)
+	}
+
+	name := p.name()
+	if name == "" {
+		return "$"
+	}
+	return p.lookup(name)
+}
+
+// lookup returns the value of a variable assigned earlier in the file, or else from the process environment
+func (p *parser) lookup(name string) string {
+	if v, ok := p.vars[name]; ok {
+		return v
+	}
+	return os.Getenv(name)
+}
@@ -21,19 +21,84 @@ var matchAllCap = regexp.MustCompile("([a-z0-9])([A-Z])")
 func NewEnvLateBindingSource(envPrefix string) cs.LateBindingSource {
 	return func(key string) (any, error) {
 
-		snake := matchFirstCap.ReplaceAllString(key, "${1}_${2}")
-		snake = matchAllCap.ReplaceAllString(snake, "${1}_${2}") // Translate the key to an env formatted name
-		snake = strings.ReplaceAll(snake, ".", "_")
-		snake = strings.ToUpper(snake)
-
-		if envPrefix != "" {
-			snake = fmt.Sprintf("%s_%s", envPrefix, snake)
+		val := os.Getenv(EnvVarName(key, envPrefix))
+		if val == "" {
+			return nil, nil
 		}
+		return val, nil
+	}
+}
+
+// NewAnnotatedEnvLateBindingSource creates a late binding source in the same way as NewEnvLateBindingSource, returning
+// values as a cs.Annotated naming the environment variable, so that cs.Config.Explain reports where they came from
+func NewAnnotatedEnvLateBindingSource(envPrefix string) cs.LateBindingSource {
+	return func(key string) (any, error) {
+
+		snake := EnvVarName(key, envPrefix)
 
 		val := os.Getenv(snake)
 		if val == "" {
 			return nil, nil
 		}
-		return val, nil
+		return cs.Annotated{
+			Value: val,
+			Origin: cs.Origin{
+				Source: "env",
+				EnvVar: snake,
+			},
+		}, nil
+	}
+}
+
+// NewEnvKeyEnumerator creates a cs.KeyEnumerator which lists keys for environment variables, for use alongside
+// NewEnvLateBindingSource with the same envPrefix
+//
+// Upper snake case names are converted into dot-separated lower case keys, so [envPrefix]_DB_HOST is listed as
+// db.host. If envPrefix is empty, every environment variable is listed
+func NewEnvKeyEnumerator(envPrefix string) cs.KeyEnumerator {
+	return func() []string {
+		var res []string
+		for _, kv := range os.Environ() {
+			name, val, _ := strings.Cut(kv, "=")
+			if val == "" {
+				continue
+			}
+			if key, ok := EnvVarKey(name, envPrefix); ok {
+				res = append(res, key)
+			}
+		}
+		return res
+	}
+}
+
+// EnvVarName returns the environment variable name NewEnvLateBindingSource looks up for a key
+//
+// Dot-separated lower camel case keys are converted into upper snake case, so db.maxConns becomes DB_MAX_CONNS. If
+// envPrefix is non-empty, it is prepended in format [envPrefix]_[name]
+func EnvVarName(key, envPrefix string) string {
+
+	snake := matchFirstCap.ReplaceAllString(key, "${1}_${2}")
+	snake = matchAllCap.ReplaceAllString(snake, "${1}_${2}") // Translate the key to an env formatted name
+	snake = strings.ReplaceAll(snake, ".", "_")
+	snake = strings.ToUpper(snake)
+
+	if envPrefix != "" {
+		snake = fmt.Sprintf("%s_%s", envPrefix, snake)
+	}
+
+	return snake
+}
+
+// EnvVarKey returns the key NewEnvKeyEnumerator lists for an environment variable name. It is the inverse of
+// EnvVarName, except that case within a segment can't be recovered, so DB_MAX_CONNS becomes db.max.conns
+//
+// If envPrefix is non-empty, names without the [envPrefix]_ prefix return false
+func EnvVarKey(name, envPrefix string) (string, bool) {
+	if envPrefix != "" {
+		var ok bool
+		if name, ok = strings.CutPrefix(name, envPrefix+"_"); !ok {
+			return "", false
+		}
 	}
+	return strings.ToLower(strings.ReplaceAll(name, "_", ".")), true
 }
@@ -0,0 +1,87 @@
+package sources
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/activatedio/cs"
+)
+
+// glob returns the files matching any of the patterns, in lexical order
+func glob(patterns []string) ([]string, error) {
+
+	var res []string
+
+	for _, p := range patterns {
+		matches, err := filepath.Glob(p)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		res = append(res, matches...)
+	}
+
+	slices.Sort(res)
+	return slices.Compact(res), nil
+}
+
+// NewGlobSource creates a source which loads each file matching any of the patterns, in lexical order, as successive
+// layers, so that later files take precedence. This supports conf.d style directories such as /etc/app/conf.d/*.yaml
+//
+// A non-empty keyPrefix will prepend the prefix to stored keys, in format [keyPrefix].[key]
+//
+// newSource creates the source for a single file, such as yaml.NewAnnotatedSourceFromPath with an empty keyPrefix.
+// Patterns are matched each time the source is loaded, so added and removed files are picked up on reload, and matching
+// no files contributes nothing
+func NewGlobSource(keyPrefix string, newSource func(path string) cs.Source, patterns ...string) cs.Source {
+	return func() (string, any, error) {
+
+		paths, err := glob(patterns)
+
+		if err != nil {
+			return "", nil, err
+		}
+
+		layers := make(cs.Layers, 0, len(paths))
+
+		for _, p := range paths {
+
+			key, v, err := newSource(p)()
+
+			if err != nil {
+				return "", nil, fmt.Errorf("%s: %w", p, err)
+			}
+
+			if key != "" {
+				return "", nil, fmt.Errorf("%s: source for a glob must not have a key prefix, got %s", p, key)
+			}
+
+			layers = append(layers, v)
+		}
+
+		return keyPrefix, layers, nil
+	}
+}
+
+// NewGlobWatcher creates a cs.Watchable which polls the files matching any of the patterns at the provided interval,
+// reporting a change when a matching file is added, removed or changed
+func NewGlobWatcher(interval time.Duration, patterns ...string) cs.Watchable {
+	return &fileWatcher{
+		interval: interval,
+		snapshot: func() []fileState {
+
+			// An invalid pattern never matches, and is reported when the source is loaded
+			paths, _ := glob(patterns)
+
+			res := make([]fileState, 0, len(paths))
+			for _, p := range paths {
+				state := statFile(p)
+				state.path = p
+				res = append(res, state)
+			}
+
+			return res
+		},
+	}
+}
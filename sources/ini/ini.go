@@ -0,0 +1,165 @@
+// Package ini supports cs sources from ini files
+package ini
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/activatedio/cs"
+	"github.com/activatedio/cs/sources"
+	"github.com/activatedio/cs/sources/internal/kv"
+)
+
+// NewSourceFromPath creates a new source by parsing an ini file at the given path
+//
+// A non-empty keyPrefix will prepend the prefix to stored keys, in format [keyPrefix].[key]
+//
+// Keys under a [section] header are stored as section.key, and dotted section names and keys become nested maps. Keys
+// and values are separated by = or :, and lines starting with ; or # are comments. Unquoted values may end with a
+// comment preceded by whitespace, lines ending in a backslash continue onto the next line, and escapes such as \t and
+// \uXXXX are supported outside of single quotes. A key which is repeated within a section becomes a list of its values
+func NewSourceFromPath(path, keyPrefix string) cs.Source {
+	return func() (string, any, error) {
+
+		f, err := os.Open(path) //nolint:gosec // users of this library should never use user input for this value
+
+		if err != nil {
+			return "", nil, err
+		}
+
+		defer f.Close()
+
+		tree := kv.NewTree()
+
+		if err = parse(bufio.NewScanner(f), tree); err != nil {
+			return "", nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		return keyPrefix, cs.Annotated{
+			Value: tree.Values,
+			Origin: cs.Origin{
+				Source: "ini",
+				Path:   path,
+			},
+			Lines: tree.Lines,
+		}, nil
+	}
+}
+
+// parse reads each key in each section into tree
+func parse(s *bufio.Scanner, tree *kv.Tree) error {
+
+	n := 0
+	section := ""
+
+	for s.Scan() {
+
+		n++
+		line := strings.TrimSpace(s.Text())
+
+		if line == "" || line[0] == ';' || line[0] == '#' {
+			continue
+		}
+
+		if line[0] == '[' {
+			end := strings.IndexByte(line, ']')
+			if end < 0 {
+				return fmt.Errorf("line %d: unterminated section header", n)
+			}
+			if rest := strings.TrimSpace(line[end+1:]); rest != "" && rest[0] != ';' && rest[0] != '#' {
+				return fmt.Errorf("line %d: unexpected %q after section header", n, rest)
+			}
+			section = strings.TrimSpace(line[1:end])
+			continue
+		}
+
+		start := n
+
+		for {
+			var ok bool
+			if line, ok = kv.Continued(line); !ok || !s.Scan() {
+				break
+			}
+			n++
+			line += strings.TrimSpace(s.Text())
+		}
+
+		i := strings.IndexAny(line, "=:")
+		if i < 0 {
+			return fmt.Errorf("line %d: expected = or : after key", start)
+		}
+
+		key := strings.TrimSpace(line[:i])
+		if section != "" {
+			key = section + "." + key
+		}
+
+		val, err := value(strings.TrimSpace(line[i+1:]))
+		if err != nil {
+			return fmt.Errorf("line %d: %w", start, err)
+		}
+
+		if err = tree.Add(key, val, start); err != nil {
+			return err
+		}
+	}
+
+	return s.Err()
+}
+
+// value reads a quoted or unquoted value, removing any trailing comment
+func value(raw string) (string, error) {
+
+	if raw == "" {
+		return "", nil
+	}
+
+	if q := raw[0]; q == '"' || q == '\'' {
+
+		end := closingQuote(raw, q)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated quoted value")
+		}
+
+		if rest := strings.TrimSpace(raw[end+1:]); rest != "" && rest[0] != ';' && rest[0] != '#' {
+			return "", fmt.Errorf("unexpected %q after quoted value", rest)
+		}
+
+		if q == '\'' {
+			return raw[1:end], nil
+		}
+		return kv.Unescape(raw[1:end], true)
+	}
+
+	for i := 1; i < len(raw); i++ {
+		if (raw[i] == ';' || raw[i] == '#') && (raw[i-1] == ' ' || raw[i-1] == '\t') {
+			raw = strings.TrimSpace(raw[:i])
+			break
+		}
+	}
+
+	return kv.Unescape(raw, true)
+}
+
+// closingQuote returns the index of the quote closing a value starting with q, skipping escaped double quotes
+func closingQuote(raw string, q byte) int {
+	for i := 1; i < len(raw); i++ {
+		switch raw[i] {
+		case '\\':
+			if q == '"' {
+				i++
+			}
+		case q:
+			return i
+		}
+	}
+	return -1
+}
+
+// NewWatchableSourceFromPath creates a source in the same way as NewSourceFromPath, along with a cs.Watchable which
+// polls the file for changes. The results can be passed directly to cs.Config.AddWatchableSource
+func NewWatchableSourceFromPath(path, keyPrefix string) (cs.Source, cs.Watchable) {
+	return NewSourceFromPath(path, keyPrefix), sources.NewFileWatcher(sources.DefaultPollInterval, path)
+}
@@ -0,0 +1,147 @@
+// Package kv contains helpers shared by sources which read dot-separated keys and string values from line based files
+package kv
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// Tree builds nested maps from dot-separated keys
+type Tree struct {
+	// Values holds the nested maps
+	Values map[string]any
+	// Lines maps dot-separated keys to the line they were first defined on, for use in cs.Annotated
+	Lines map[string]int
+}
+
+// NewTree creates an empty Tree
+func NewTree() *Tree {
+	return &Tree{
+		Values: map[string]any{},
+		Lines:  map[string]int{},
+	}
+}
+
+// Add stores a value at a dot-separated key, recording the line it was defined on. A key which is repeated becomes a
+// list of its values, in order
+func (t *Tree) Add(key, val string, line int) error {
+
+	path := strings.Split(key, ".")
+	for _, p := range path {
+		if p == "" {
+			return fmt.Errorf("line %d: invalid key %q", line, key)
+		}
+	}
+
+	m := t.Values
+	for i, p := range path[:len(path)-1] {
+		switch v := m[p].(type) {
+		case nil:
+			next := map[string]any{}
+			m[p] = next
+			m = next
+		case map[string]any:
+			m = v
+		default:
+			return fmt.Errorf("line %d: key %s conflicts with the value of %s", line, key, strings.Join(path[:i+1], "."))
+		}
+	}
+
+	last := path[len(path)-1]
+
+	switch v := m[last].(type) {
+	case nil:
+		m[last] = val
+		t.Lines[key] = line
+	case string:
+		m[last] = []any{v, val}
+	case []any:
+		m[last] = append(v, val)
+	default:
+		return fmt.Errorf("line %d: key %s conflicts with keys under it", line, key)
+	}
+
+	return nil
+}
+
+// Continued removes a trailing backslash which continues a line onto the next. Backslashes which are themselves
+// escaped don't continue the line
+func Continued(line string) (string, bool) {
+	n := len(line) - len(strings.TrimRight(line, `\`))
+	if n%2 == 0 {
+		return line, false
+	}
+	return line[:len(line)-1], true
+}
+
+// Unescape replaces the escapes \t, \n, \r, \f, \uXXXX, escaped quotes and escaped backslashes in s. Any other escaped
+// character is kept as is, so \= becomes =, unless keepUnknown is set, in which case the backslash is kept too
+func Unescape(s string, keepUnknown bool) (string, error) {
+
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+
+	var sb strings.Builder
+
+	for i := 0; i < len(s); i++ {
+
+		c := s[i]
+		if c != '\\' || i == len(s)-1 {
+			sb.WriteByte(c)
+			continue
+		}
+
+		i++
+		switch e := s[i]; e {
+		case 't':
+			sb.WriteByte('\t')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 'f':
+			sb.WriteByte('\f')
+		case '\\', '"', '\'':
+			sb.WriteByte(e)
+		case 'u':
+			r, err := unicodeEscape(s[i:])
+			if err != nil {
+				return "", err
+			}
+			i += 4
+			// Characters outside the basic multilingual plane are escaped as a surrogate pair
+			if utf16.IsSurrogate(r) && strings.HasPrefix(s[i+1:], `\u`) {
+				if r2, err := unicodeEscape(s[i+2:]); err == nil {
+					if dr := utf16.DecodeRune(r, r2); dr != unicode.ReplacementChar {
+						r = dr
+						i += 6
+					}
+				}
+			}
+			sb.WriteRune(r)
+		default:
+			if keepUnknown {
+				sb.WriteByte('\\')
+			}
+			sb.WriteByte(e)
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// unicodeEscape parses the four hex digits following u at the start of s
+func unicodeEscape(s string) (rune, error) {
+	if len(s) < 5 || s[0] != 'u' {
+		return 0, fmt.Errorf("invalid unicode escape \\%s", s[:min(len(s), 5)])
+	}
+	r, err := strconv.ParseUint(s[1:5], 16, 16)
+	if err != nil {
+		return 0, fmt.Errorf("invalid unicode escape \\%s", s[:5])
+	}
+	return rune(r), nil
+}
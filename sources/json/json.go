@@ -4,8 +4,10 @@ package json
 import (
 	"encoding/json"
 	"os"
+	"path/filepath"
 
 	"github.com/activatedio/cs"
+	"github.com/activatedio/cs/sources"
 )
 
 // NewSourceFromPath creates a new source by parsing a json file at the given path
@@ -33,3 +35,72 @@ func NewSourceFromPath(path, keyPrefix string) cs.Source {
 		return keyPrefix, res, nil
 	}
 }
+
+// NewAnnotatedSourceFromPath creates a source in the same way as NewSourceFromPath, returning the parsed map as a
+// cs.Annotated recording the path, so that cs.Config.Explain reports where values came from
+func NewAnnotatedSourceFromPath(path, keyPrefix string) cs.Source {
+
+	src := NewSourceFromPath(path, keyPrefix)
+
+	return func() (string, any, error) {
+
+		key, res, err := src()
+
+		if err != nil {
+			return "", nil, err
+		}
+
+		return key, cs.Annotated{
+			Value: res,
+			Origin: cs.Origin{
+				Source: "json",
+				Path:   path,
+			},
+		}, nil
+	}
+}
+
+// NewWatchableSourceFromPath creates a source in the same way as NewSourceFromPath, along with a cs.Watchable which
+// polls the file for changes. The results can be passed directly to cs.Config.AddWatchableSource
+func NewWatchableSourceFromPath(path, keyPrefix string) (cs.Source, cs.Watchable) {
+	return NewSourceFromPath(path, keyPrefix), sources.NewFileWatcher(sources.DefaultPollInterval, path)
+}
+
+// Decode parses json data, for use with sources.WithDecoder
+func Decode(data []byte) (any, error) {
+	var res any
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// NewSourceFromGlob creates a source which loads each json file matching a pattern, such as /etc/app/conf.d/*.json, in
+// lexical order as successive layers, so that later files take precedence
+//
+// A non-empty keyPrefix will prepend the prefix to stored keys, in format [keyPrefix].[key]
+func NewSourceFromGlob(pattern, keyPrefix string) cs.Source {
+	return sources.NewGlobSource(keyPrefix, newSourceFromPath, pattern)
+}
+
+// NewWatchableSourceFromGlob creates a source in the same way as NewSourceFromGlob, along with a cs.Watchable which
+// polls the matching files for changes, including files being added or removed
+func NewWatchableSourceFromGlob(pattern, keyPrefix string) (cs.Source, cs.Watchable) {
+	return NewSourceFromGlob(pattern, keyPrefix), sources.NewGlobWatcher(sources.DefaultPollInterval, pattern)
+}
+
+// NewSourceFromDirectory creates a source which loads each file in a directory with the extension .json, in
+// lexical order, in the same way as NewSourceFromGlob
+func NewSourceFromDirectory(dir, keyPrefix string) cs.Source {
+	return sources.NewGlobSource(keyPrefix, newSourceFromPath, filepath.Join(dir, "*.json"))
+}
+
+// NewWatchableSourceFromDirectory creates a source in the same way as NewSourceFromDirectory, along with a cs.Watchable
+// which polls the matching files for changes, including files being added or removed
+func NewWatchableSourceFromDirectory(dir, keyPrefix string) (cs.Source, cs.Watchable) {
+	return NewSourceFromDirectory(dir, keyPrefix), sources.NewGlobWatcher(sources.DefaultPollInterval, filepath.Join(dir, "*.json"))
+}
+
+func newSourceFromPath(path string) cs.Source {
+	return NewAnnotatedSourceFromPath(path, "")
+}
@@ -0,0 +1,130 @@
+// Package properties supports cs sources from Java style .properties files
+package properties
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/activatedio/cs"
+	"github.com/activatedio/cs/sources"
+	"github.com/activatedio/cs/sources/internal/kv"
+)
+
+// NewSourceFromPath creates a new source by parsing a .properties file at the given path
+//
+// A non-empty keyPrefix will prepend the prefix to stored keys, in format [keyPrefix].[key]
+//
+// Dotted keys such as db.host become nested maps. Keys and values are separated by =, : or whitespace, lines ending in
+// a backslash continue onto the next line, and escapes such as \t and \uXXXX are supported. A key which is repeated
+// becomes a list of its values
+func NewSourceFromPath(path, keyPrefix string) cs.Source {
+	return func() (string, any, error) {
+
+		f, err := os.Open(path) //nolint:gosec // users of this library should never use user input for this value
+
+		if err != nil {
+			return "", nil, err
+		}
+
+		defer f.Close()
+
+		tree := kv.NewTree()
+
+		if err = parse(bufio.NewScanner(f), tree); err != nil {
+			return "", nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		return keyPrefix, cs.Annotated{
+			Value: tree.Values,
+			Origin: cs.Origin{
+				Source: "properties",
+				Path:   path,
+			},
+			Lines: tree.Lines,
+		}, nil
+	}
+}
+
+// parse reads each property into tree
+func parse(s *bufio.Scanner, tree *kv.Tree) error {
+
+	n := 0
+
+	for s.Scan() {
+
+		n++
+		line := strings.TrimLeft(s.Text(), " \t\f")
+
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+
+		start := n
+
+		// Leading whitespace of continuation lines is ignored
+		for {
+			var ok bool
+			if line, ok = kv.Continued(line); !ok || !s.Scan() {
+				break
+			}
+			n++
+			line += strings.TrimLeft(s.Text(), " \t\f")
+		}
+
+		key, val := split(line)
+
+		key, err := kv.Unescape(key, false)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", start, err)
+		}
+
+		val, err = kv.Unescape(val, false)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", start, err)
+		}
+
+		if err = tree.Add(key, val, start); err != nil {
+			return err
+		}
+	}
+
+	return s.Err()
+}
+
+// split separates a property into its key and value. The key ends at the first unescaped =, : or whitespace, which may
+// be surrounded by whitespace
+func split(line string) (string, string) {
+
+	i := 0
+	for ; i < len(line); i++ {
+		c := line[i]
+		if c == '\\' {
+			i++
+			continue
+		}
+		if c == '=' || c == ':' || c == ' ' || c == '\t' || c == '\f' {
+			break
+		}
+	}
+
+	if i >= len(line) {
+		return line, ""
+	}
+
+	key := line[:i]
+	rest := strings.TrimLeft(line[i:], " \t\f")
+
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+
+	return key, rest
+}
+
+// NewWatchableSourceFromPath creates a source in the same way as NewSourceFromPath, along with a cs.Watchable which
+// polls the file for changes. The results can be passed directly to cs.Config.AddWatchableSource
+func NewWatchableSourceFromPath(path, keyPrefix string) (cs.Source, cs.Watchable) {
+	return NewSourceFromPath(path, keyPrefix), sources.NewFileWatcher(sources.DefaultPollInterval, path)
+}
@@ -1,6 +1,11 @@
 package sources
 
-import "github.com/activatedio/cs"
+import (
+	"errors"
+	"io/fs"
+
+	"github.com/activatedio/cs"
+)
 
 // NewSource returns the given value for a cs key
 func NewSource(key string, val any) cs.Source {
@@ -8,3 +13,15 @@ func NewSource(key string, val any) cs.Source {
 		return key, val, nil
 	}
 }
+
+// Optional wraps a source so that a missing file is skipped rather than failing the load, for files such as local
+// overrides which may not exist
+func Optional(src cs.Source) cs.Source {
+	return func() (string, any, error) {
+		key, v, err := src()
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", nil, nil
+		}
+		return key, v, err
+	}
+}
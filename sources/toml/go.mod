@@ -0,0 +1,18 @@
+module github.com/activatedio/cs/sources/toml
+
+go 1.22.0
+
+require (
+	github.com/BurntSushi/toml v1.5.0
+	github.com/activatedio/cs v0.0.0
+	github.com/stretchr/testify v1.10.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/spf13/cast v1.9.2 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/activatedio/cs => ../..
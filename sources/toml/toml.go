@@ -0,0 +1,84 @@
+// Package toml supports cs sources from toml files
+//
+// The package is a separate module, so the core module doesn't depend on a toml library
+package toml
+
+import (
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/activatedio/cs"
+	"github.com/activatedio/cs/sources"
+)
+
+// NewSourceFromPath creates a new source by parsing a toml file at the given path
+//
+// A non-empty keyPrefix will prepend the prefix to stored keys, in format [keyPrefix].[key]
+//
+// Tables become maps and arrays of tables become lists of maps. Offset datetimes are read as time.Time, while local
+// dates, times and datetimes, which have no zone, are read as strings such as "2006-01-02T15:04:05" which can be read
+// into a time.Time
+func NewSourceFromPath(path, keyPrefix string) cs.Source {
+	return func() (string, any, error) {
+
+		res := map[string]any{}
+
+		_, err := toml.DecodeFile(path, &res)
+
+		if err != nil {
+			return "", nil, err
+		}
+
+		return keyPrefix, cs.Annotated{
+			Value: normalize(res),
+			Origin: cs.Origin{
+				Source: "toml",
+				Path:   path,
+			},
+		}, nil
+	}
+}
+
+// localLayouts are the layouts local dates, times and datetimes are formatted with, keyed by the name of the zone
+// they are decoded with
+var localLayouts = map[string]string{
+	"datetime-local": "2006-01-02T15:04:05.999999999",
+	"date-local":     time.DateOnly,
+	"time-local":     "15:04:05.999999999",
+}
+
+// normalize converts values decoded from toml to the types accepted by cs sources
+func normalize(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, _v := range val {
+			val[k] = normalize(_v)
+		}
+		return val
+	case []map[string]any:
+		res := make([]any, 0, len(val))
+		for _, m := range val {
+			res = append(res, normalize(m))
+		}
+		return res
+	case []any:
+		for i, _v := range val {
+			val[i] = normalize(_v)
+		}
+		return val
+	case time.Time:
+		// Local values are decoded with a zone named for their kind, and have no meaningful offset
+		if layout, ok := localLayouts[val.Location().String()]; ok {
+			return val.Format(layout)
+		}
+		return val
+	default:
+		return v
+	}
+}
+
+// NewWatchableSourceFromPath creates a source in the same way as NewSourceFromPath, along with a cs.Watchable which
+// polls the file for changes. The results can be passed directly to cs.Config.AddWatchableSource
+func NewWatchableSourceFromPath(path, keyPrefix string) (cs.Source, cs.Watchable) {
+	return NewSourceFromPath(path, keyPrefix), sources.NewFileWatcher(sources.DefaultPollInterval, path)
+}
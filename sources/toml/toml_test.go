@@ -0,0 +1,76 @@
+package toml_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/activatedio/cs"
+	"github.com/activatedio/cs/sources/toml"
+	"github.com/stretchr/testify/assert"
+)
+
+type Server struct {
+	Host string
+	Port int
+}
+
+type Config struct {
+	DisplayName  string
+	SleepSeconds int
+	Enabled      bool
+	Ratio        float64
+	Started      time.Time
+	Birthday     time.Time
+	WakeUp       string
+	Deployed     time.Time
+	Database     map[string]struct {
+		Host  string
+		Ports []int
+	}
+	Servers []Server
+}
+
+func TestNewSourceFromPath(t *testing.T) {
+
+	a := assert.New(t)
+
+	unit := cs.NewConfig()
+	unit.AddSource(toml.NewSourceFromPath("testdata/config.toml", "app"))
+
+	got := Config{}
+	a.NoError(unit.Read("app", &got))
+
+	a.Equal("Display Name", got.DisplayName)
+	a.Equal(60, got.SleepSeconds)
+	a.True(got.Enabled)
+	a.InDelta(0.5, got.Ratio, 0)
+	a.True(time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC).Equal(got.Started))
+	a.Equal(time.Date(1990, 5, 17, 0, 0, 0, 0, time.UTC), got.Birthday)
+	a.Equal("07:30:00", got.WakeUp)
+	a.Equal(time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC), got.Deployed)
+	a.Equal("dbhost", got.Database["primary"].Host)
+	a.Equal([]int{5432, 5433}, got.Database["primary"].Ports)
+	a.Equal([]Server{
+		{Host: "server1", Port: 8080},
+		{Host: "server2", Port: 8081},
+	}, got.Servers)
+
+	var title string
+	a.NoError(unit.Read("app.content.title", &title))
+	a.Equal("Some title", title)
+
+	explanation, err := unit.Explain("app.content.footer")
+	a.NoError(err)
+	a.Equal(cs.Origin{Source: "toml", Path: "testdata/config.toml"}, explanation.Origin)
+}
+
+func TestNewSourceFromPath_Invalid(t *testing.T) {
+
+	a := assert.New(t)
+
+	unit := cs.NewConfig()
+	unit.AddSource(toml.NewSourceFromPath("testdata/missing.toml", ""))
+
+	var se *cs.SourceError
+	a.ErrorAs(unit.Read("", &map[string]any{}), &se)
+}
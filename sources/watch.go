@@ -0,0 +1,101 @@
+package sources
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/activatedio/cs"
+)
+
+// DefaultPollInterval is the interval at which watched files are checked for changes
+const DefaultPollInterval = 2 * time.Second
+
+type fileState struct {
+	// path identifies the file within a directory, so that files being added or removed are detected
+	path string
+	// target is the destination of a symlink which is swapped atomically, such as the ..data link in Kubernetes mounts
+	target  string
+	exists  bool
+	modTime time.Time
+	size    int64
+}
+
+func statFile(path string) fileState {
+	// Stat follows symlinks, so replacing the target of a symlink is also detected
+	fi, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{
+		exists:  true,
+		modTime: fi.ModTime(),
+		size:    fi.Size(),
+	}
+}
+
+type fileWatcher struct {
+	interval time.Duration
+	snapshot func() []fileState
+}
+
+// NewFileWatcher creates a cs.Watchable which polls the given files at the provided interval, reporting a change when
+// a file is created, removed, or its modification time or size changes
+//
+// Polling is used rather than filesystem notifications so that no additional dependencies are required and files
+// replaced through symlinks, such as those mounted by Kubernetes, are handled
+func NewFileWatcher(interval time.Duration, paths ...string) cs.Watchable {
+	return &fileWatcher{
+		interval: interval,
+		snapshot: func() []fileState {
+			res := make([]fileState, 0, len(paths))
+			for _, p := range paths {
+				res = append(res, statFile(p))
+			}
+			return res
+		},
+	}
+}
+
+func (w *fileWatcher) Watch(notify func()) func() {
+
+	done := make(chan struct{})
+	last := w.snapshot()
+
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				current := w.snapshot()
+				if changed(last, current) {
+					last = current
+					notify()
+				}
+			}
+		}
+	}()
+
+	once := sync.Once{}
+	return func() {
+		once.Do(func() {
+			close(done)
+		})
+	}
+}
+
+func changed(a, b []fileState) bool {
+	if len(a) != len(b) {
+		return true
+	}
+	for i := range a {
+		if a[i].path != b[i].path || a[i].target != b[i].target || a[i].exists != b[i].exists || a[i].size != b[i].size ||
+			!a[i].modTime.Equal(b[i].modTime) {
+			return true
+		}
+	}
+	return false
+}
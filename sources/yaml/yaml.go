@@ -3,8 +3,10 @@ package yaml
 
 import (
 	"os"
+	"path/filepath"
 
 	"github.com/activatedio/cs"
+	"github.com/activatedio/cs/sources"
 	"gopkg.in/yaml.v3"
 )
 
@@ -14,22 +16,133 @@ import (
 func NewSourceFromPath(path, keyPrefix string) cs.Source {
 	return func() (string, any, error) {
 
-		res := map[string]any{}
-
-		f, err := os.Open(path) //nolint:gosec // users of this library should never use user input for this value
+		res, _, err := read(path)
 
 		if err != nil {
 			return "", nil, err
 		}
 
-		defer f.Close()
+		return keyPrefix, res, nil
+	}
+}
+
+// NewAnnotatedSourceFromPath creates a source in the same way as NewSourceFromPath, returning the parsed map as a
+// cs.Annotated recording the path and the line of each key, so that cs.Config.Explain reports where values came from
+func NewAnnotatedSourceFromPath(path, keyPrefix string) cs.Source {
+	return func() (string, any, error) {
 
-		err = yaml.NewDecoder(f).Decode(&res)
+		res, lines, err := read(path)
 
 		if err != nil {
 			return "", nil, err
 		}
 
-		return keyPrefix, res, nil
+		return keyPrefix, cs.Annotated{
+			Value: res,
+			Origin: cs.Origin{
+				Source: "yaml",
+				Path:   path,
+			},
+			Lines: lines,
+		}, nil
 	}
 }
+
+// read parses the yaml file at the given path, along with the line each key is defined on
+func read(path string) (map[string]any, map[string]int, error) {
+
+	res := map[string]any{}
+
+	f, err := os.Open(path) //nolint:gosec // users of this library should never use user input for this value
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer f.Close()
+
+	node := &yaml.Node{}
+
+	err = yaml.NewDecoder(f).Decode(node)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = node.Decode(&res)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lines := map[string]int{}
+	collectLines(node, "", lines)
+
+	return res, lines, nil
+}
+
+// collectLines records the line each key of a mapping is defined on
+func collectLines(node *yaml.Node, prefix string, lines map[string]int) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, n := range node.Content {
+			collectLines(n, prefix, lines)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			lines[key] = node.Content[i].Line
+			collectLines(node.Content[i+1], key, lines)
+		}
+	default:
+		return
+	}
+}
+
+// NewWatchableSourceFromPath creates a source in the same way as NewSourceFromPath, along with a cs.Watchable which
+// polls the file for changes. The results can be passed directly to cs.Config.AddWatchableSource
+func NewWatchableSourceFromPath(path, keyPrefix string) (cs.Source, cs.Watchable) {
+	return NewSourceFromPath(path, keyPrefix), sources.NewFileWatcher(sources.DefaultPollInterval, path)
+}
+
+// Decode parses yaml data, for use with sources.WithDecoder
+func Decode(data []byte) (any, error) {
+	var res any
+	if err := yaml.Unmarshal(data, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// NewSourceFromGlob creates a source which loads each yaml file matching a pattern, such as /etc/app/conf.d/*.yaml, in
+// lexical order as successive layers, so that later files take precedence
+//
+// A non-empty keyPrefix will prepend the prefix to stored keys, in format [keyPrefix].[key]
+func NewSourceFromGlob(pattern, keyPrefix string) cs.Source {
+	return sources.NewGlobSource(keyPrefix, newSourceFromPath, pattern)
+}
+
+// NewWatchableSourceFromGlob creates a source in the same way as NewSourceFromGlob, along with a cs.Watchable which
+// polls the matching files for changes, including files being added or removed
+func NewWatchableSourceFromGlob(pattern, keyPrefix string) (cs.Source, cs.Watchable) {
+	return NewSourceFromGlob(pattern, keyPrefix), sources.NewGlobWatcher(sources.DefaultPollInterval, pattern)
+}
+
+// NewSourceFromDirectory creates a source which loads each file in a directory with the extension .yaml or .yml, in
+// lexical order, in the same way as NewSourceFromGlob
+func NewSourceFromDirectory(dir, keyPrefix string) cs.Source {
+	return sources.NewGlobSource(keyPrefix, newSourceFromPath, filepath.Join(dir, "*.yaml"), filepath.Join(dir, "*.yml"))
+}
+
+// NewWatchableSourceFromDirectory creates a source in the same way as NewSourceFromDirectory, along with a cs.Watchable
+// which polls the matching files for changes, including files being added or removed
+func NewWatchableSourceFromDirectory(dir, keyPrefix string) (cs.Source, cs.Watchable) {
+	return NewSourceFromDirectory(dir, keyPrefix), sources.NewGlobWatcher(sources.DefaultPollInterval, filepath.Join(dir, "*.yaml"), filepath.Join(dir, "*.yml"))
+}
+
+func newSourceFromPath(path string) cs.Source {
+	return NewAnnotatedSourceFromPath(path, "")
+}
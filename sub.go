@@ -0,0 +1,266 @@
+package cs
+
+import (
+	"context"
+	"io"
+	"strings"
+)
+
+// subConfig is a view of a parent config scoped to a key prefix. All keys are relative to the prefix, and sources
+// added to the view are stored under the prefix in the parent
+type subConfig struct {
+	parent Config
+	prefix string
+}
+
+func newSubConfig(parent Config, prefix string) Config {
+	if prefix == "" {
+		return parent
+	}
+	if s, ok := parent.(*subConfig); ok {
+		// Nested views are flattened onto the root config
+		return &subConfig{
+			parent: s.parent,
+			prefix: joinKey(s.prefix, prefix),
+		}
+	}
+	return &subConfig{
+		parent: parent,
+		prefix: prefix,
+	}
+}
+
+func (s *subConfig) key(key string) string {
+	return joinKey(s.prefix, key)
+}
+
+// relative strips the prefix from a full key. The second return value is false if the key is not under the prefix
+func (s *subConfig) relative(key string) (string, bool) {
+	if key == s.prefix {
+		return "", true
+	}
+	return strings.CutPrefix(key, s.prefix+".")
+}
+
+// options adapts the options of a read to the parent, prefixing the keys passed to Required, which are relative to the
+// view
+func (s *subConfig) options(opts []Option) []Option {
+	if len(opts) == 0 {
+		return opts
+	}
+	return []Option{func(o *readOptions) {
+		base := o.required
+		o.required = nil
+		for _, opt := range opts {
+			opt(o)
+		}
+		required := make(map[string]bool, len(base)+len(o.required))
+		for k := range base {
+			required[k] = true
+		}
+		for k := range o.required {
+			required[s.key(k)] = true
+		}
+		o.required = required
+	}}
+}
+
+func (s *subConfig) source(src Source) Source {
+	return func() (string, any, error) {
+		key, v, err := src()
+		return s.key(key), v, err
+	}
+}
+
+func (s *subConfig) AddSource(src Source) {
+	s.parent.AddSource(s.source(src))
+}
+
+func (s *subConfig) AddLateBindingSource(src LateBindingSource) {
+	s.parent.AddLateBindingSource(func(key string) (any, error) {
+		if rel, ok := s.relative(key); ok {
+			return src(rel)
+		}
+		return nil, nil
+	})
+}
+
+func (s *subConfig) AddKeyEnumerator(e KeyEnumerator) {
+	s.parent.AddKeyEnumerator(func() []string {
+		keys := e()
+		res := make([]string, 0, len(keys))
+		for _, k := range keys {
+			res = append(res, s.key(k))
+		}
+		return res
+	})
+}
+
+func (s *subConfig) AddSourceProvider(p SourceProvider) {
+	s.parent.AddSourceProvider(s.sourceProvider(p))
+}
+
+func (s *subConfig) AddLateBindingProvider(p LateBindingProvider) {
+	s.parent.AddLateBindingProvider(&prefixedLateBindingProvider{
+		sub:      s,
+		delegate: p,
+	})
+}
+
+// RemoveSource removes a source by name. Names are shared with the parent config
+func (s *subConfig) RemoveSource(name string) error {
+	return s.parent.RemoveSource(name)
+}
+
+// ReplaceSource replaces a source by name. Names are shared with the parent config
+func (s *subConfig) ReplaceSource(name string, p SourceProvider) error {
+	return s.parent.ReplaceSource(name, s.sourceProvider(p))
+}
+
+// RemoveLateBindingSource removes a late binding source by name. Names are shared with the parent config
+func (s *subConfig) RemoveLateBindingSource(name string) error {
+	return s.parent.RemoveLateBindingSource(name)
+}
+
+func (s *subConfig) sourceProvider(p SourceProvider) SourceProvider {
+	return &prefixedSourceProvider{
+		sub:      s,
+		delegate: p,
+	}
+}
+
+// prefixedSourceProvider stores the results of a provider under the prefix of a sub config, passing through
+// watching and closing
+type prefixedSourceProvider struct {
+	sub      *subConfig
+	delegate SourceProvider
+}
+
+func (p *prefixedSourceProvider) Name() string {
+	return p.delegate.Name()
+}
+
+func (p *prefixedSourceProvider) Load(ctx context.Context) (string, any, error) {
+	key, v, err := p.delegate.Load(ctx)
+	return p.sub.key(key), v, err
+}
+
+func (p *prefixedSourceProvider) Watch(notify func()) func() {
+	if w, ok := p.delegate.(Watchable); ok {
+		return w.Watch(notify)
+	}
+	return func() {}
+}
+
+func (p *prefixedSourceProvider) Close() error {
+	if c, ok := p.delegate.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// prefixedLateBindingProvider only answers lookups for keys under the prefix of a sub config, passing the key
+// relative to the prefix
+type prefixedLateBindingProvider struct {
+	sub      *subConfig
+	delegate LateBindingProvider
+}
+
+func (p *prefixedLateBindingProvider) Name() string {
+	return p.delegate.Name()
+}
+
+func (p *prefixedLateBindingProvider) Lookup(ctx context.Context, key string) (any, error) {
+	if rel, ok := p.sub.relative(key); ok {
+		return p.delegate.Lookup(ctx, rel)
+	}
+	return nil, nil
+}
+
+func (p *prefixedLateBindingProvider) Watch(notify func()) func() {
+	if w, ok := p.delegate.(Watchable); ok {
+		return w.Watch(notify)
+	}
+	return func() {}
+}
+
+func (p *prefixedLateBindingProvider) Close() error {
+	if c, ok := p.delegate.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+func (s *subConfig) AddWatchableSource(src Source, w Watchable) {
+	s.parent.AddWatchableSource(s.source(src), w)
+}
+
+func (s *subConfig) Subscribe(key string, fn func(old, new any)) func() {
+	return s.parent.Subscribe(s.key(key), fn)
+}
+
+// Close has no effect, as watched sources are owned by the parent config
+func (s *subConfig) Close() {
+}
+
+func (s *subConfig) Read(key string, into any, opts ...Option) error {
+	return s.parent.Read(s.key(key), into, s.options(opts)...)
+}
+
+func (s *subConfig) ReadContext(ctx context.Context, key string, into any, opts ...Option) error {
+	return s.parent.ReadContext(ctx, s.key(key), into, s.options(opts)...)
+}
+
+func (s *subConfig) MustRead(key string, into any, opts ...Option) {
+	s.parent.MustRead(s.key(key), into, s.options(opts)...)
+}
+
+func (s *subConfig) Has(key string) (bool, error) {
+	return s.parent.Has(s.key(key))
+}
+
+func (s *subConfig) Keys(prefix string) ([]string, error) {
+	keys, err := s.parent.Keys(s.key(prefix))
+	if err != nil {
+		return nil, err
+	}
+	res := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if rel, ok := s.relative(k); ok && rel != "" {
+			res = append(res, rel)
+		}
+	}
+	return res, nil
+}
+
+func (s *subConfig) Children(key string) ([]string, error) {
+	return s.parent.Children(s.key(key))
+}
+
+func (s *subConfig) AllSettings() (map[string]any, error) {
+	all, err := s.parent.AllSettings()
+	if err != nil {
+		return nil, err
+	}
+	for _, part := range strings.Split(s.prefix, ".") {
+		next, ok := all[part].(map[string]any)
+		if !ok {
+			return map[string]any{}, nil
+		}
+		all = next
+	}
+	return all, nil
+}
+
+// Explain returns the explanation for the key relative to the prefix. The key of the explanation is the full key
+func (s *subConfig) Explain(key string) (*Explanation, error) {
+	return s.parent.Explain(s.key(key))
+}
+
+func (s *subConfig) Sub(prefix string) Config {
+	return newSubConfig(s, prefix)
+}
+
+func (s *subConfig) CacheStats() CacheStats {
+	return s.parent.CacheStats()
+}
@@ -0,0 +1,118 @@
+package cs_test
+
+import (
+	"testing"
+
+	"github.com/activatedio/cs"
+	"github.com/stretchr/testify/assert"
+)
+
+type BillingDB struct {
+	Host string
+	Port int
+}
+
+func TestSub(t *testing.T) {
+
+	a := assert.New(t)
+
+	unit := cs.NewConfig()
+	unit.AddSource(func() (string, any, error) {
+		return "services", map[string]any{
+			"billing": map[string]any{
+				"db": map[string]any{
+					"host": "dbhost",
+					"port": 5432,
+				},
+			},
+		}, nil
+	})
+	unit.AddLateBindingSource(func(key string) (any, error) {
+		if key == "services.billing.db.port" {
+			return "5433", nil
+		}
+		return nil, nil
+	})
+
+	sub := unit.Sub("services.billing")
+
+	var host string
+	sub.MustRead("db.host", &host)
+	a.Equal("dbhost", host)
+
+	db := &BillingDB{}
+	sub.Sub("db").MustRead("", db)
+	a.Equal(&BillingDB{Host: "dbhost", Port: 5433}, db)
+
+	found, err := sub.Has("db.host")
+	a.NoError(err)
+	a.True(found)
+
+	found, err = sub.Has("services")
+	a.NoError(err)
+	a.False(found)
+
+	keys, err := sub.Keys("")
+	a.NoError(err)
+	a.Equal([]string{"db.host", "db.port"}, keys)
+
+	children, err := sub.Children("db")
+	a.NoError(err)
+	a.Equal([]string{"host", "port"}, children)
+
+	all, err := sub.AllSettings()
+	a.NoError(err)
+	a.Equal(map[string]any{
+		"db": map[string]any{
+			"host": "dbhost",
+			"port": 5433,
+		},
+	}, all)
+
+	// Sources and late binding sources added to the view are relative to its prefix
+	sub.AddSource(func() (string, any, error) {
+		return "db.user", "dbuser", nil
+	})
+	sub.AddLateBindingSource(func(key string) (any, error) {
+		if key == "db.password" {
+			return "secret", nil
+		}
+		return nil, nil
+	})
+
+	var user, password string
+	unit.MustRead("services.billing.db.user", &user)
+	unit.MustRead("services.billing.db.password", &password)
+	a.Equal("dbuser", user)
+	a.Equal("secret", password)
+
+	a.Equal(unit, unit.Sub(""))
+}
+
+func TestSub_Required(t *testing.T) {
+
+	a := assert.New(t)
+
+	unit := cs.NewConfig()
+	unit.AddSource(func() (string, any, error) {
+		return "services.billing.db", map[string]any{
+			"host": "dbhost",
+		}, nil
+	})
+
+	sub := unit.Sub("services.billing")
+
+	// Required keys are relative to the view
+	db := &BillingDB{}
+	a.NoError(sub.Read("db", db, cs.Required("db.host")))
+	a.Equal("dbhost", db.Host)
+
+	err := sub.Read("db", &BillingDB{}, cs.Required("db.host", "db.port"))
+	a.ErrorIs(err, cs.ErrKeyNotFound)
+	a.ErrorContains(err, "services.billing.db.port")
+
+	err = sub.Sub("db").Read("", &BillingDB{}, cs.Required("port"))
+	a.ErrorIs(err, cs.ErrKeyNotFound)
+
+	a.NoError(sub.Sub("db").Read("", &BillingDB{}, cs.Required("host")))
+}
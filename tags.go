@@ -0,0 +1,132 @@
+package cs
+
+import (
+	"reflect"
+	"strings"
+)
+
+// tagName is the struct tag consulted for field options, in format `cs:"name,default=value,required,omitempty,squash"`
+const tagName = "cs"
+
+// fallbackTagNames are consulted for the field name, in order, when no name is given in the cs tag
+var fallbackTagNames = []string{"json", "yaml"}
+
+// fieldInfo holds the options for a single struct field
+type fieldInfo struct {
+	// name is the key of the field within its parent
+	name string
+	// defaultValue is used when no source or late binding source supplies a value
+	defaultValue string
+	hasDefault   bool
+	// required fails the read when no value is present
+	required bool
+	// omitEmpty skips zero values when the struct is used as a source
+	omitEmpty bool
+	// squash flattens the fields of a struct field into its parent
+	squash bool
+}
+
+// parseField reads the field options of a struct field. The second return value is false if the field should be skipped
+func parseField(field reflect.StructField) (fieldInfo, bool) {
+
+	info := fieldInfo{}
+
+	if !field.IsExported() && !(field.Anonymous && field.Type.Kind() == reflect.Struct) {
+		return info, false
+	}
+
+	tag, hasTag := field.Tag.Lookup(tagName)
+	if tag == "-" {
+		return info, false
+	}
+
+	name, opts, _ := strings.Cut(tag, ",")
+	info.name = name
+
+	if hasTag {
+		for opts != "" {
+			var opt string
+			opt, opts = cutOption(opts)
+			switch {
+			case opt == "required":
+				info.required = true
+			case opt == "omitempty":
+				info.omitEmpty = true
+			case opt == "squash":
+				info.squash = true
+			case strings.HasPrefix(opt, "default="):
+				info.defaultValue = strings.TrimPrefix(opt, "default=")
+				info.hasDefault = true
+			}
+		}
+	}
+
+	for _, fb := range fallbackTagNames {
+		fbTag, ok := field.Tag.Lookup(fb)
+		if !ok {
+			continue
+		}
+		if fbTag == "-" && !hasTag {
+			return info, false
+		}
+		fbName, fbOpts, _ := strings.Cut(fbTag, ",")
+		if info.name == "" {
+			info.name = fbName
+		}
+		for _, opt := range strings.Split(fbOpts, ",") {
+			switch opt {
+			case "omitempty":
+				info.omitEmpty = true
+			case "inline":
+				info.squash = true
+			}
+		}
+	}
+
+	// Embedded structs are flattened unless given an explicit name
+	if field.Anonymous && info.name == "" && field.Type.Kind() == reflect.Struct {
+		info.squash = true
+	}
+
+	if info.name == "" {
+		info.name = toLowerCamel(field.Name)
+	}
+
+	return info, true
+}
+
+// cutOption returns the next option from a comma separated list of options. Default values extend up to the next
+// recognized option, which allows them to contain commas
+func cutOption(opts string) (string, string) {
+	if !strings.HasPrefix(opts, "default=") {
+		opt, rest, _ := strings.Cut(opts, ",")
+		return opt, rest
+	}
+	parts := strings.Split(opts, ",")
+	for i := 1; i < len(parts); i++ {
+		if isFieldOption(parts[i]) {
+			return strings.Join(parts[:i], ","), strings.Join(parts[i:], ",")
+		}
+	}
+	return opts, ""
+}
+
+func isFieldOption(opt string) bool {
+	switch opt {
+	case "required", "omitempty", "squash":
+		return true
+	default:
+		return strings.HasPrefix(opt, "default=")
+	}
+}
+
+// joinKey joins a key to a prefix in dot format
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	if key == "" {
+		return prefix
+	}
+	return prefix + "." + key
+}
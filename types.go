@@ -1,10 +1,15 @@
 package cs
 
+import "context"
+
 // Source and LateBindingSource can return
 // map[string]any
 // struct
 // any primitive type, expect byte, or uintptr
 // slice of any of the above types
+// Annotated, wrapping any of the above types with its origin
+//
+// A Source can also return Layers, supplying several of the above as successive layers under its key
 
 // Source returns a key, the cs object, and an error
 type Source func() (string, any, error)
@@ -12,6 +17,27 @@ type Source func() (string, any, error)
 // LateBindingSource source returns a cs value for a given key at the time a csuration is read
 type LateBindingSource func(key string) (any, error)
 
+// KeyEnumerator lists the keys, in dot format, which a late binding source can currently satisfy. Late binding
+// sources can't otherwise be enumerated, so this allows their keys to be included in Has, Keys, Children and
+// AllSettings
+type KeyEnumerator func() []string
+
+// Watchable is implemented by sources which can report changes to their underlying data
+type Watchable interface {
+	// Watch invokes notify whenever the underlying data may have changed, until the returned stop function is called
+	Watch(notify func()) (stop func())
+}
+
+// CacheStats reports the effectiveness of the read cache
+type CacheStats struct {
+	// Hits is the number of reads served from the cache
+	Hits uint64
+	// Misses is the number of reads which were resolved from sources
+	Misses uint64
+	// Entries is the number of values currently cached
+	Entries int
+}
+
 // Config is main interface for cs data.  Keys are in dot format, `prefix.name`
 type Config interface {
 
@@ -23,10 +49,86 @@ type Config interface {
 	// underlying results are looked up again with provided keys
 	AddLateBindingSource(src LateBindingSource)
 
+	// AddSourceProvider adds a named source in the same way as AddSource. Adding a provider with the name of an
+	// existing provider replaces it in place
+	AddSourceProvider(p SourceProvider)
+
+	// AddLateBindingProvider adds a named late binding source in the same way as AddLateBindingSource. Adding a
+	// provider with the name of an existing provider replaces it in place
+	AddLateBindingProvider(p LateBindingProvider)
+
+	// RemoveSource removes the source with the given name. Sources added with AddSource are named by the order they
+	// were added, in format source[n]. Returns ErrSourceNotFound if no source has the name
+	RemoveSource(name string) error
+
+	// ReplaceSource replaces the source with the given name, keeping its precedence. Returns ErrSourceNotFound if no
+	// source has the name
+	ReplaceSource(name string, p SourceProvider) error
+
+	// RemoveLateBindingSource removes the late binding source with the given name. Late binding sources added with
+	// AddLateBindingSource are named in format late binding source[n]. Returns ErrSourceNotFound if no late binding
+	// source has the name
+	RemoveLateBindingSource(name string) error
+
+	// AddKeyEnumerator adds a function listing keys available from late binding sources
+	AddKeyEnumerator(e KeyEnumerator)
+
+	// AddWatchableSource adds a source in the same way as AddSource. Each time the watchable reports a change, all
+	// sources are invoked again and subscribers are notified of changed values
+	AddWatchableSource(src Source, w Watchable)
+
+	// Subscribe registers a function which is called with the old and new value of a key when a reload changes it.
+	// Values are passed in their natural form, with maps as map[string]any and lists as []any. The returned function
+	// removes the subscription
+	Subscribe(key string, fn func(old, new any)) (unsubscribe func())
+
+	// Close stops watching all watchable sources and closes all providers which implement io.Closer
+	Close()
+
 	// Read reads value from the key and assigns it to the provided object, which must be a pointer to a supported value
-	// supported values are all primitives and a map
-	Read(key string, into any) error
+	// supported values are all primitives, maps, structs, slices and arrays, along with well known types such as
+	// time.Duration, time.Time, url.URL, net.IP, *net.IPNet, netip.Addr, *regexp.Regexp, big.Int, ByteSize and
+	// fs.FileMode, which are parsed from strings
+	//
+	// Struct fields are keyed by the lower camel case field name, which can be changed with a
+	// `cs:"name,default=value,required,omitempty,squash"` tag. json and yaml tags are used as fallbacks for the name
+	//
+	// Once populated, values are checked against `validate` tags and the Validator interface. All failures are
+	// returned together in a *ValidationError
+	//
+	// Options apply to this read only, after the options the config was created with
+	Read(key string, into any, opts ...Option) error
+
+	// ReadContext reads in the same way as Read. The context is passed to sources loaded by the read and to late
+	// binding sources. If the context is done before a source completes, a *TimeoutError naming the source is returned
+	ReadContext(ctx context.Context, key string, into any, opts ...Option) error
 
 	// MustRead reads and panics on error
-	MustRead(key string, into any)
+	MustRead(key string, into any, opts ...Option)
+
+	// Has returns true if a value is present for the key, either from sources, late binding sources or the keys
+	// listed by key enumerators
+	Has(key string) (bool, error)
+
+	// Keys returns the sorted full keys of all values under the prefix which are not maps. An empty prefix returns
+	// all keys
+	Keys(prefix string) ([]string, error)
+
+	// Children returns the sorted names of the immediate children of the key
+	Children(key string) ([]string, error)
+
+	// AllSettings returns all values as nested maps, with late binding sources applied
+	AllSettings() (map[string]any, error)
+
+	// Explain returns the winning value of a key along with every value supplied for it by sources and late binding
+	// sources, and where each came from
+	Explain(key string) (*Explanation, error)
+
+	// Sub returns a view of the config scoped to the key prefix. Reads, existence checks, subscriptions and sources
+	// added to the view are relative to the prefix. The view shares the sources, cache and reload behavior of this
+	// config
+	Sub(prefix string) Config
+
+	// CacheStats returns statistics for the read cache
+	CacheStats() CacheStats
 }
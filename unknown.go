@@ -0,0 +1,109 @@
+package cs
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// unknownKeys returns an *UnknownKeyError for each key in the map which doesn't match a field of the struct type,
+// including the fields of squashed structs
+func unknownKeys(fullKey string, typ reflect.Type, valMap map[string]reflect.Value) []error {
+
+	known := map[string]bool{}
+	collectFieldNames(typ, known)
+
+	var unknown []string
+	for k := range valMap {
+		if !known[k] {
+			unknown = append(unknown, k)
+		}
+	}
+	sort.Strings(unknown)
+
+	var errs []error
+	for _, k := range unknown {
+		e := &UnknownKeyError{
+			Key: joinKey(fullKey, k),
+		}
+		if s, ok := suggest(k, known); ok {
+			e.Suggestion = joinKey(fullKey, s)
+		}
+		errs = append(errs, e)
+	}
+
+	return errs
+}
+
+func collectFieldNames(typ reflect.Type, names map[string]bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		info, ok := parseField(field)
+		if !ok {
+			continue
+		}
+		if info.squash && field.Type.Kind() == reflect.Struct {
+			collectFieldNames(field.Type, names)
+			continue
+		}
+		if info.squash && field.Type.Kind() == reflect.Ptr && field.Type.Elem().Kind() == reflect.Struct {
+			collectFieldNames(field.Type.Elem(), names)
+			continue
+		}
+		names[info.name] = true
+	}
+}
+
+// suggest returns the name closest to the key, if it is close enough to be a likely misspelling
+func suggest(key string, names map[string]bool) (string, bool) {
+
+	best := ""
+	bestDist := -1
+	for name := range names {
+		d := editDistance(strings.ToLower(key), strings.ToLower(name))
+		// Ties are broken alphabetically so suggestions are stable
+		if bestDist < 0 || d < bestDist || (d == bestDist && name < best) {
+			best = name
+			bestDist = d
+		}
+	}
+
+	limit := len(key) / 3
+	if limit < 1 {
+		limit = 1
+	}
+	if bestDist < 0 || bestDist > limit {
+		return "", false
+	}
+	return best, true
+}
+
+// editDistance returns the optimal string alignment distance between two strings, which counts insertions, deletions,
+// substitutions and transpositions of adjacent characters
+func editDistance(a, b string) int {
+
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return d[len(ra)][len(rb)]
+}
@@ -0,0 +1,72 @@
+package cs_test
+
+import (
+	"testing"
+
+	"github.com/activatedio/cs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStrictKeys(t *testing.T) {
+
+	type Common struct {
+		Name string `cs:"name"`
+	}
+
+	type Database struct {
+		Host string `cs:"host"`
+		Port int    `cs:"port"`
+	}
+
+	type Server struct {
+		Common
+		Database Database       `cs:"database"`
+		Labels   map[string]any `cs:"labels"`
+	}
+
+	a := assert.New(t)
+
+	unit := cs.NewConfig()
+	unit.AddSource(func() (string, any, error) {
+		return "server", map[string]any{
+			"name": "api",
+			"databse": map[string]any{
+				"host": "localhost",
+			},
+			"database": map[string]any{
+				"hsot": "localhost",
+				"port": 5432,
+			},
+			"labels": map[string]any{
+				"anything": "goes",
+			},
+			"timeout": "5s",
+		}, nil
+	})
+
+	// Unknown keys are ignored by default
+	got := Server{}
+	a.NoError(unit.Read("server", &got))
+	a.Equal(5432, got.Database.Port)
+
+	err := unit.Read("server", &Server{}, cs.WithStrictKeys(true))
+
+	var ae *cs.AggregateError
+	a.ErrorAs(err, &ae)
+	a.Len(ae.Errors, 3)
+
+	var ue *cs.UnknownKeyError
+	a.ErrorAs(ae.Errors[0], &ue)
+	a.Equal("server.database.hsot", ue.Key)
+	a.Equal("server.database.host", ue.Suggestion)
+	a.EqualError(ae.Errors[0], "key server.database.hsot: unknown key, did you mean server.database.host?")
+
+	a.ErrorAs(ae.Errors[1], &ue)
+	a.Equal("server.databse", ue.Key)
+	a.Equal("server.database", ue.Suggestion)
+
+	a.ErrorAs(ae.Errors[2], &ue)
+	a.Equal("server.timeout", ue.Key)
+	a.Empty(ue.Suggestion)
+	a.EqualError(ae.Errors[2], "key server.timeout: unknown key")
+}
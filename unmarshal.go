@@ -0,0 +1,138 @@
+package cs
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/spf13/cast"
+)
+
+// Unmarshaler is implemented by types which populate themselves from config. UnmarshalConfig receives the raw merged
+// value of the key, which is a string, number or bool, a []any for lists or a map[string]any for maps
+type Unmarshaler interface {
+	UnmarshalConfig(raw any) error
+}
+
+// DecodeHook converts a raw value, in the same form passed to Unmarshaler, to the type it is registered for
+type DecodeHook func(raw any) (any, error)
+
+var unmarshalerType = reflect.TypeFor[Unmarshaler]()
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+var jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+
+var globalDecodeHooks = map[reflect.Type]DecodeHook{}
+var globalDecodeHooksLock sync.RWMutex
+
+// RegisterDecodeHook registers a hook used by every config to read values of type T. Hooks given to a config with
+// WithDecodeHook take precedence. Hooks should be registered before reading, as cached reads are not affected
+func RegisterDecodeHook[T any](hook func(raw any) (T, error)) {
+	globalDecodeHooksLock.Lock()
+	defer globalDecodeHooksLock.Unlock()
+
+	globalDecodeHooks[reflect.TypeFor[T]()] = toDecodeHook(hook)
+}
+
+// WithDecodeHook registers a hook used to read values of type T. Hooks take precedence over Unmarshaler,
+// encoding.TextUnmarshaler, json.Unmarshaler and the built in decoding of well known types
+func WithDecodeHook[T any](hook func(raw any) (T, error)) Option {
+	typ := reflect.TypeFor[T]()
+	h := toDecodeHook(hook)
+	return func(o *readOptions) {
+		// The map is copied, as it may be shared with the options of the config
+		hooks := make(map[reflect.Type]DecodeHook, len(o.decodeHooks)+1)
+		for k, v := range o.decodeHooks {
+			hooks[k] = v
+		}
+		hooks[typ] = h
+		o.decodeHooks = hooks
+	}
+}
+
+func toDecodeHook[T any](hook func(raw any) (T, error)) DecodeHook {
+	return func(raw any) (any, error) {
+		return hook(raw)
+	}
+}
+
+// decoderFor returns the decoder for a destination type, or nil if values of the type are populated by kind. In order
+// of precedence, decoders are decode hooks given to the config or read, global decode hooks, Unmarshaler, the
+// decoders of well known types, encoding.TextUnmarshaler and json.Unmarshaler
+func decoderFor(opts readOptions, typ reflect.Type) decoder {
+
+	if hook, ok := opts.decodeHooks[typ]; ok {
+		return hookDecoder(hook)
+	}
+
+	globalDecodeHooksLock.RLock()
+	hook, ok := globalDecodeHooks[typ]
+	globalDecodeHooksLock.RUnlock()
+	if ok {
+		return hookDecoder(hook)
+	}
+
+	ptr := reflect.PointerTo(typ)
+	switch {
+	case ptr.Implements(unmarshalerType):
+		return unmarshalDecoder(typ, func(dest any, raw any) error {
+			return dest.(Unmarshaler).UnmarshalConfig(raw)
+		})
+	case wellKnownDecoders[typ] != nil:
+		return wellKnownDecoders[typ]
+	case ptr.Implements(textUnmarshalerType):
+		return unmarshalDecoder(typ, unmarshalText)
+	case ptr.Implements(jsonUnmarshalerType):
+		return unmarshalDecoder(typ, unmarshalJSON)
+	default:
+		return nil
+	}
+}
+
+func hookDecoder(hook DecodeHook) decoder {
+	return func(_ readOptions, raw any) (any, error) {
+		return hook(raw)
+	}
+}
+
+// unmarshalDecoder adapts a function which unmarshals into a pointer to a decoder
+func unmarshalDecoder(typ reflect.Type, unmarshal func(dest any, raw any) error) decoder {
+	return func(_ readOptions, raw any) (any, error) {
+		ptr := reflect.New(typ)
+		if err := unmarshal(ptr.Interface(), raw); err != nil {
+			return nil, err
+		}
+		return ptr.Elem().Interface(), nil
+	}
+}
+
+func unmarshalText(dest any, raw any) error {
+	switch raw.(type) {
+	case map[string]any, []any:
+		if u, ok := dest.(json.Unmarshaler); ok {
+			// Types supporting both read maps and lists as json
+			return unmarshalJSON(u, raw)
+		}
+		return fmt.Errorf("cannot unmarshal %T as text", raw)
+	}
+	s, err := cast.ToStringE(raw)
+	if err != nil {
+		return err
+	}
+	return dest.(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+}
+
+func unmarshalJSON(dest any, raw any) error {
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	u := dest.(json.Unmarshaler)
+	err = u.UnmarshalJSON(b)
+	if s, ok := raw.(string); ok && err != nil && json.Valid([]byte(s)) {
+		// Strings from sources such as environment variables may hold json documents
+		return u.UnmarshalJSON([]byte(s))
+	}
+	return err
+}
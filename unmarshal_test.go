@@ -0,0 +1,172 @@
+package cs_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/activatedio/cs"
+	"github.com/stretchr/testify/assert"
+)
+
+type testLevel int
+
+func (l *testLevel) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "debug":
+		*l = 1
+	case "info":
+		*l = 2
+	default:
+		return fmt.Errorf("unknown level %s", text)
+	}
+	return nil
+}
+
+type testPoint struct {
+	X, Y int
+}
+
+func (p *testPoint) UnmarshalJSON(b []byte) error {
+	var v [2]int
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	p.X, p.Y = v[0], v[1]
+	return nil
+}
+
+type testPeers struct {
+	names []string
+	raw   any
+}
+
+func (p *testPeers) UnmarshalConfig(raw any) error {
+	p.raw = raw
+	switch v := raw.(type) {
+	case map[string]any:
+		for k := range v {
+			p.names = append(p.names, k)
+		}
+	case string:
+		p.names = strings.Split(v, " ")
+	default:
+		return errors.New("unsupported peers")
+	}
+	return nil
+}
+
+type testID string
+
+func TestUnmarshalers(t *testing.T) {
+
+	type Config struct {
+		Level  testLevel   `cs:"level"`
+		Levels []testLevel `cs:"levels"`
+		Origin testPoint   `cs:"origin"`
+		Peers  testPeers   `cs:"peers"`
+	}
+
+	a := assert.New(t)
+
+	unit := cs.NewConfig()
+	unit.AddSource(func() (string, any, error) {
+		return "app", map[string]any{
+			"level":  "debug",
+			"levels": []any{"info", "DEBUG"},
+			"origin": []any{1, 2},
+			"peers": map[string]any{
+				"a": map[string]any{"port": 1},
+			},
+		}, nil
+	})
+
+	got := Config{}
+	a.NoError(unit.Read("app", &got))
+	a.Equal(testLevel(1), got.Level)
+	a.Equal([]testLevel{2, 1}, got.Levels)
+	a.Equal(testPoint{X: 1, Y: 2}, got.Origin)
+	a.Equal([]string{"a"}, got.Peers.names)
+	a.Equal(map[string]any{"a": map[string]any{"port": 1}}, got.Peers.raw)
+
+	// Strings from late binding sources are unmarshaled in the same way
+	unit.AddLateBindingSource(func(key string) (any, error) {
+		switch key {
+		case "app.level":
+			return "info", nil
+		case "app.origin":
+			return "[3,4]", nil
+		}
+		return nil, nil
+	})
+
+	got = Config{}
+	a.NoError(unit.Read("app", &got))
+	a.Equal(testLevel(2), got.Level)
+	a.Equal(testPoint{X: 3, Y: 4}, got.Origin)
+
+	unit = cs.NewConfig()
+	unit.AddSource(func() (string, any, error) {
+		return "app.level", "verbose", nil
+	})
+
+	var level testLevel
+	var ce *cs.ConversionError
+	a.ErrorAs(unit.Read("app.level", &level), &ce)
+	a.EqualError(ce, `key app.level: cannot convert string "verbose" from source source[0] to cs_test.testLevel: unknown level verbose`)
+}
+
+func TestDecodeHooks(t *testing.T) {
+
+	a := assert.New(t)
+
+	cs.RegisterDecodeHook(func(raw any) (testID, error) {
+		return testID("global-" + fmt.Sprint(raw)), nil
+	})
+
+	src := func() (string, any, error) {
+		return "app", map[string]any{
+			"id":    "a1",
+			"level": 3,
+		}, nil
+	}
+
+	unit := cs.NewConfig()
+	unit.AddSource(src)
+
+	var id testID
+	a.NoError(unit.Read("app.id", &id))
+	a.Equal(testID("global-a1"), id)
+
+	unit = cs.NewConfig(
+		cs.WithDecodeHook(func(raw any) (testID, error) {
+			return testID("config-" + fmt.Sprint(raw)), nil
+		}),
+		// Hooks take precedence over encoding.TextUnmarshaler
+		cs.WithDecodeHook(func(raw any) (testLevel, error) {
+			return testLevel(raw.(int)), nil
+		}),
+	)
+	unit.AddSource(src)
+
+	a.NoError(unit.Read("app.id", &id))
+	a.Equal(testID("config-a1"), id)
+
+	var level testLevel
+	a.NoError(unit.Read("app.level", &level))
+	a.Equal(testLevel(3), level)
+
+	a.NoError(unit.Read("app.id", &id, cs.WithDecodeHook(func(raw any) (testID, error) {
+		return testID("read-" + fmt.Sprint(raw)), nil
+	})))
+	a.Equal(testID("read-a1"), id)
+
+	err := unit.Read("app.id", &id, cs.WithDecodeHook(func(raw any) (testID, error) {
+		return "", errors.New("invalid id")
+	}))
+	var ce *cs.ConversionError
+	a.ErrorAs(err, &ce)
+	a.Equal("app.id", ce.Key)
+}
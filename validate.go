@@ -0,0 +1,251 @@
+package cs
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// validateTagName is the struct tag holding validation rules, in format `validate:"required,min=1,max=65535"`
+//
+// Rules are checked in order and only the first failure of each field is reported. Supported rules are:
+//
+//	required  - the value must not be the zero value, or empty for strings, slices and maps
+//	omitempty - skips the remaining rules when the value is empty
+//	min=n     - numbers must be at least n, strings, slices and maps must have a length of at least n
+//	max=n     - numbers must be at most n, strings, slices and maps must have a length of at most n
+//	oneof=a b - the value must be one of the space separated values
+//	url       - the value must be an absolute url
+//	hostname  - the value must be a valid RFC 1123 hostname
+const validateTagName = "validate"
+
+// Validator is implemented by types which validate themselves after being read. Validate is called after all
+// validation tags on the type's fields have been checked
+type Validator interface {
+	Validate() error
+}
+
+// FieldError describes a single failed validation
+type FieldError struct {
+	// Key is the full dot separated key of the value
+	Key string
+	// Rule is the rule which failed, or "Validate" for errors returned from a Validator
+	Rule string
+	// Err describes the failure
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	if e.Key == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s: %s", e.Key, e.Err.Error())
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError is returned from reads when one or more values fail validation. It lists every failure
+type ValidationError struct {
+	Errors []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		msgs = append(msgs, fe.Error())
+	}
+	return fmt.Sprintf("validation failed: %s", strings.Join(msgs, "; "))
+}
+
+var hostnameRegexp = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)(\.[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*\.?$`)
+
+// validate checks validation tags and Validator implementations on a populated value and its children
+func validate(fullKey string, val reflect.Value) error {
+	var errs []*FieldError
+	collectValidationErrors(fullKey, val, &errs)
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+func collectValidationErrors(fullKey string, val reflect.Value, errs *[]*FieldError) {
+
+	switch val.Kind() {
+	case reflect.Struct:
+		for i := 0; i < val.NumField(); i++ {
+			field := val.Type().Field(i)
+			info, ok := parseField(field)
+			if !ok {
+				continue
+			}
+			_fullKey := fullKey
+			if !info.squash {
+				_fullKey = joinKey(fullKey, info.name)
+			}
+			f := val.Field(i)
+			if tag, hasTag := field.Tag.Lookup(validateTagName); hasTag {
+				if fe := checkRules(_fullKey, f, tag); fe != nil {
+					*errs = append(*errs, fe)
+				}
+			}
+			collectValidationErrors(_fullKey, f, errs)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < val.Len(); i++ {
+			collectValidationErrors(joinKey(fullKey, strconv.Itoa(i)), val.Index(i), errs)
+		}
+	case reflect.Map:
+		iter := val.MapRange()
+		for iter.Next() {
+			collectValidationErrors(joinKey(fullKey, fmt.Sprint(iter.Key().Interface())), iter.Value(), errs)
+		}
+	case reflect.Ptr, reflect.Interface:
+		// Values behind pointers and interfaces are validated in place
+		if !val.IsNil() {
+			collectValidationErrors(fullKey, val.Elem(), errs)
+		}
+		return
+	default:
+		return
+	}
+
+	if v, ok := asValidator(val); ok {
+		if err := v.Validate(); err != nil {
+			*errs = append(*errs, &FieldError{
+				Key:  fullKey,
+				Rule: "Validate",
+				Err:  err,
+			})
+		}
+	}
+}
+
+func asValidator(val reflect.Value) (Validator, bool) {
+	if val.CanAddr() && val.Addr().CanInterface() {
+		if v, ok := val.Addr().Interface().(Validator); ok {
+			return v, true
+		}
+	}
+	if val.CanInterface() {
+		if v, ok := val.Interface().(Validator); ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// checkRules checks each rule in a validation tag in order, stopping at the first failure
+func checkRules(fullKey string, val reflect.Value, tag string) *FieldError {
+
+	// Rules other than required apply to the value a pointer points to, and are skipped for nil pointers
+	elem := val
+	for elem.Kind() == reflect.Ptr && !elem.IsNil() {
+		elem = elem.Elem()
+	}
+
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		if name == "omitempty" {
+			if isEmptyValue(val) {
+				return nil
+			}
+			continue
+		}
+		target := elem
+		if name == "required" {
+			target = val
+		} else if elem.Kind() == reflect.Ptr {
+			continue
+		}
+		if err := checkRule(target, name, param); err != nil {
+			return &FieldError{
+				Key:  fullKey,
+				Rule: name,
+				Err:  err,
+			}
+		}
+	}
+
+	return nil
+}
+
+func checkRule(val reflect.Value, name, param string) error { //nolint:gocyclo // switch statement ok for readability
+	switch name {
+	case "required":
+		if isEmptyValue(val) {
+			return errors.New("is required")
+		}
+	case "min", "max":
+		limit, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s parameter %q", name, param)
+		}
+		n, isLen, ok := measure(val)
+		if !ok {
+			return fmt.Errorf("rule %s is not supported for kind %s", name, val.Kind().String())
+		}
+		what := "be"
+		if isLen {
+			what = "have a length"
+		}
+		if name == "min" && n < limit {
+			return fmt.Errorf("must %s at least %s", what, param)
+		}
+		if name == "max" && n > limit {
+			return fmt.Errorf("must %s at most %s", what, param)
+		}
+	case "oneof":
+		s := fmt.Sprint(val.Interface())
+		for _, opt := range strings.Fields(param) {
+			if s == opt {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of [%s]", param)
+	case "url":
+		u, err := url.Parse(fmt.Sprint(val.Interface()))
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return errors.New("must be an absolute url")
+		}
+	case "hostname":
+		s := fmt.Sprint(val.Interface())
+		if len(s) > 253 || !hostnameRegexp.MatchString(s) {
+			return errors.New("must be a valid hostname")
+		}
+	default:
+		return fmt.Errorf("unknown validation rule %s", name)
+	}
+	return nil
+}
+
+// measure returns the number used by min and max rules, and whether it is a length
+func measure(val reflect.Value) (float64, bool, bool) {
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(val.Int()), false, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(val.Uint()), false, true
+	case reflect.Float32, reflect.Float64:
+		return val.Float(), false, true
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return float64(val.Len()), true, true
+	default:
+		return 0, false, false
+	}
+}
+
+func isEmptyValue(val reflect.Value) bool {
+	switch val.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return val.Len() == 0
+	default:
+		return val.IsZero()
+	}
+}
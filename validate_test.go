@@ -0,0 +1,166 @@
+package cs_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/activatedio/cs"
+	"github.com/stretchr/testify/assert"
+)
+
+type DatabaseConfig struct {
+	Host string `validate:"required,hostname"`
+	Port int    `validate:"min=1,max=65535"`
+}
+
+type ServerConfig struct {
+	Mode     string `validate:"oneof=dev prod"`
+	Endpoint string `validate:"omitempty,url"`
+	Database DatabaseConfig
+	Replicas []DatabaseConfig
+	Workers  int
+}
+
+func (s *ServerConfig) Validate() error {
+	if s.Workers > 10 {
+		return errors.New("too many workers")
+	}
+	return nil
+}
+
+func TestValidation(t *testing.T) {
+
+	a := assert.New(t)
+
+	type s struct {
+		arrange func(c cs.Config)
+		assert  func(got *ServerConfig, err error)
+	}
+
+	cases := map[string]s{
+		"valid": {
+			arrange: func(c cs.Config) {
+				c.AddSource(func() (string, any, error) {
+					return "server", map[string]any{
+						"mode":     "prod",
+						"endpoint": "https://example.org/path",
+						"database": map[string]any{
+							"host": "db.example.org",
+							"port": 5432,
+						},
+					}, nil
+				})
+			},
+			assert: func(got *ServerConfig, err error) {
+				a.NoError(err)
+				a.Equal("db.example.org", got.Database.Host)
+			},
+		},
+		"invalid": {
+			arrange: func(c cs.Config) {
+				c.AddSource(func() (string, any, error) {
+					return "server", map[string]any{
+						"mode":     "test",
+						"endpoint": "not a url",
+						"database": map[string]any{
+							"port": 70000,
+						},
+						"replicas": []any{
+							map[string]any{
+								"host": "bad_host!",
+								"port": 1,
+							},
+						},
+						"workers": 11,
+					}, nil
+				})
+			},
+			assert: func(_ *ServerConfig, err error) {
+				var ve *cs.ValidationError
+				a.ErrorAs(err, &ve)
+				keys := map[string]string{}
+				for _, fe := range ve.Errors {
+					keys[fe.Key] = fe.Rule
+				}
+				a.Equal(map[string]string{
+					"server.mode":            "oneof",
+					"server.endpoint":        "url",
+					"server.database.host":   "required",
+					"server.database.port":   "max",
+					"server.replicas.0.host": "hostname",
+					"server":                 "Validate",
+				}, keys)
+				a.Contains(err.Error(), "server.database.port: must be at most 65535")
+				a.Contains(err.Error(), "server: too many workers")
+			},
+		},
+	}
+
+	for k, v := range cases {
+		t.Run(k, func(_ *testing.T) {
+			unit := cs.NewConfig()
+			v.arrange(unit)
+			got := &ServerConfig{}
+			err := unit.Read("server", got)
+			v.assert(got, err)
+		})
+	}
+}
+
+func TestValidation_Pointers(t *testing.T) {
+
+	a := assert.New(t)
+
+	type Config struct {
+		Port    *int    `cs:"port" validate:"min=1"`
+		Mode    *string `cs:"mode" validate:"oneof=a b"`
+		Name    *string `cs:"name" validate:"required"`
+		Timeout *int    `cs:"timeout" validate:"max=10"`
+	}
+
+	type s struct {
+		values map[string]any
+		assert func(got *Config, err error)
+	}
+
+	cases := map[string]s{
+		"valid": {
+			values: map[string]any{"port": 5, "mode": "a", "name": "api"},
+			assert: func(got *Config, err error) {
+				a.NoError(err)
+				a.Equal(5, *got.Port)
+				a.Equal("a", *got.Mode)
+				a.Nil(got.Timeout)
+			},
+		},
+		"invalid": {
+			values: map[string]any{"port": 0, "mode": "c", "timeout": 11},
+			assert: func(_ *Config, err error) {
+				var ve *cs.ValidationError
+				a.ErrorAs(err, &ve)
+				keys := map[string]string{}
+				for _, fe := range ve.Errors {
+					keys[fe.Key] = fe.Rule
+				}
+				a.Equal(map[string]string{
+					"app.port":    "min",
+					"app.mode":    "oneof",
+					"app.name":    "required",
+					"app.timeout": "max",
+				}, keys)
+				a.Contains(err.Error(), "app.port: must be at least 1")
+			},
+		},
+	}
+
+	for k, v := range cases {
+		t.Run(k, func(_ *testing.T) {
+			unit := cs.NewConfig()
+			unit.AddSource(func() (string, any, error) {
+				return "app", v.values, nil
+			})
+			got := &Config{}
+			v.assert(got, unit.Read("app", got))
+		})
+	}
+}
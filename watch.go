@@ -0,0 +1,115 @@
+package cs
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type subscription struct {
+	key string
+	fn  func(old, new any)
+}
+
+// onChange is invoked by watched sources. If the rebuild fails, the cs is left dirty so the error is returned
+// by the next read
+func (c *cs) onChange() {
+	c.lock.Lock()
+	c.dirty = true
+	c.gen++
+	c.lock.Unlock()
+
+	_ = c.withCleanData(context.Background(), func() error {
+		return nil
+	})
+}
+
+func (c *cs) Subscribe(key string, fn func(old, new any)) func() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	id := c.nextSubscriptionID
+	c.nextSubscriptionID++
+	c.subscriptions[id] = &subscription{
+		key: key,
+		fn:  fn,
+	}
+
+	return func() {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+
+		delete(c.subscriptions, id)
+	}
+}
+
+// subscribers returns the current subscriptions in the order they were added. Must be called with the lock held
+func (c *cs) subscribers() []*subscription {
+	ids := make([]int, 0, len(c.subscriptions))
+	for id := range c.subscriptions {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	res := make([]*subscription, 0, len(ids))
+	for _, id := range ids {
+		res = append(res, c.subscriptions[id])
+	}
+	return res
+}
+
+func (c *cs) notifySubscribers(subs []*subscription, oldRoot, newRoot map[string]reflect.Value) {
+	for _, sub := range subs {
+		oldVal := toInterface(lookupValue(oldRoot, sub.key))
+		newVal := toInterface(lookupValue(newRoot, sub.key))
+		if !reflect.DeepEqual(oldVal, newVal) {
+			sub.fn(oldVal, newVal)
+		}
+	}
+}
+
+// lookupValue returns the value stored under a dot separated key, or an invalid value if not present
+func lookupValue(root map[string]reflect.Value, key string) reflect.Value {
+	if key == "" {
+		return reflect.ValueOf(root)
+	}
+	data := root
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		tmp, ok := data[part]
+		if !ok {
+			return reflect.Value{}
+		}
+		if i == len(parts)-1 {
+			return tmp
+		}
+		if data, ok = tmp.Interface().(map[string]reflect.Value); !ok {
+			return reflect.Value{}
+		}
+	}
+	return reflect.Value{}
+}
+
+// toInterface converts a stored value to its natural go representation, using map[string]any for maps and []any
+// for lists
+func toInterface(val reflect.Value) any {
+	if !val.IsValid() {
+		return nil
+	}
+	switch v := val.Interface().(type) {
+	case map[string]reflect.Value:
+		res := make(map[string]any, len(v))
+		for k, _v := range v {
+			res[k] = toInterface(_v)
+		}
+		return res
+	case []reflect.Value:
+		res := make([]any, 0, len(v))
+		for _, _v := range v {
+			res = append(res, toInterface(_v))
+		}
+		return res
+	default:
+		return v
+	}
+}